@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// swaggerdoc-gen generates SwaggerDoc methods returning the documentation of
+// the API types and their fields, sourced from the comments in the types file.
+// The JSON Schema of the KubeOneCluster manifest uses them for descriptions.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func main() {
+	headerFile := flag.String("go-header-file", "", "file with the header of the generated file")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatalf("usage: %s [--go-header-file <file>] <types.go>", os.Args[0])
+	}
+	typesFile := flag.Arg(0)
+
+	var buf bytes.Buffer
+
+	if *headerFile != "" {
+		header, err := ioutil.ReadFile(*headerFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		buf.Write(header)
+		buf.WriteString("\n")
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by swaggerdoc-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", filepath.Base(filepath.Dir(typesFile)))
+
+	if err := runtime.WriteSwaggerDocFunc(runtime.ParseDocumentationFrom(typesFile), &buf); err != nil {
+		log.Fatal(err)
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err = os.Stdout.Write(out); err != nil {
+		log.Fatal(err)
+	}
+}
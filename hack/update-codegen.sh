@@ -30,3 +30,9 @@ bash vendor/k8s.io/code-generator/generate-internal-groups.sh \
   "kubeone:v1alpha1,v1beta1" \
   --go-header-file hack/boilerplate/boilerplate.generatego.txt
 
+
+for version in v1alpha1 v1beta1; do
+  go run ./hack/swaggerdoc-gen \
+    --go-header-file hack/boilerplate/boilerplate.generatego.txt \
+    ./pkg/apis/kubeone/${version}/types.go > ./pkg/apis/kubeone/${version}/types_swagger_doc_generated.go
+done
@@ -49,7 +49,7 @@ func Ensure(s *state.State) error {
 	}
 	s.Logger.Infoln("Applying addons...")
 
-	creds, err := credentials.Any(s.CredentialsFilePath)
+	creds, err := credentials.Any(s.Cluster.CloudProvider, s.CredentialsFilePath)
 	if err != nil {
 		return errors.Wrap(err, "unable to fetch credentials")
 	}
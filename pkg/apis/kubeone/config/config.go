@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -30,6 +31,9 @@ import (
 	kubeonev1alpha1 "k8c.io/kubeone/pkg/apis/kubeone/v1alpha1"
 	kubeonev1beta1 "k8c.io/kubeone/pkg/apis/kubeone/v1beta1"
 	"k8c.io/kubeone/pkg/apis/kubeone/validation"
+	"k8c.io/kubeone/pkg/credentials"
+	"k8c.io/kubeone/pkg/inventory"
+	"k8c.io/kubeone/pkg/terraform"
 	terraformv1alpha1 "k8c.io/kubeone/pkg/terraform/v1alpha1"
 	terraformv1beta1 "k8c.io/kubeone/pkg/terraform/v1beta1"
 
@@ -54,50 +58,183 @@ var (
 	}
 )
 
+type loadOptions struct {
+	allowUnknownFields bool
+	secrets            *[]string
+	inventoryPath      string
+	credentialsExec    string
+}
+
+// LoadOption configures loading of the KubeOneCluster manifest
+type LoadOption func(*loadOptions)
+
+// AllowUnknownFields configures whether fields unknown to the API version of
+// the manifest are ignored instead of rejected
+func AllowUnknownFields(allow bool) LoadOption {
+	return func(opts *loadOptions) {
+		opts.allowUnknownFields = allow
+	}
+}
+
+// CollectSecrets configures the slice the values interpolated into the
+// manifest fields holding credentials are appended to, to be redacted from
+// the logs
+func CollectSecrets(secrets *[]string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.secrets = secrets
+	}
+}
+
+// Inventory configures the Ansible inventory the control plane and static
+// worker hosts are sourced from
+func Inventory(inventoryPath string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.inventoryPath = inventoryPath
+	}
+}
+
+// CredentialsExec configures the command printing the credentials, overriding
+// the credentials plugin configured in the manifest
+func CredentialsExec(command string) LoadOption {
+	return func(opts *loadOptions) {
+		opts.credentialsExec = command
+	}
+}
+
+func newLoadOptions(opts ...LoadOption) *loadOptions {
+	options := &loadOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
 // LoadKubeOneCluster returns the internal representation of the KubeOneCluster object
-// parsed from the versioned KubeOneCluster manifest, Terraform output and credentials file
-func LoadKubeOneCluster(clusterCfgPath, tfOutputPath, credentialsFilePath string, logger logrus.FieldLogger) (*kubeoneapi.KubeOneCluster, error) {
-	if len(clusterCfgPath) == 0 {
-		return nil, errors.New("cluster configuration path not provided")
+// parsed from the versioned KubeOneCluster manifests, inventory, Terraform output and credentials file.
+// The manifests are merged in the given order.
+func LoadKubeOneCluster(clusterCfgPaths []string, tfOutputPath, credentialsFilePath string, logger logrus.FieldLogger, opts ...LoadOption) (*kubeoneapi.KubeOneCluster, error) {
+	options := newLoadOptions(opts...)
+
+	in, err := readInputs(clusterCfgPaths, tfOutputPath, credentialsFilePath, options.inventoryPath)
+	if err != nil {
+		return nil, err
 	}
 
-	cluster, err := ioutil.ReadFile(clusterCfgPath)
+	if options.secrets != nil {
+		*options.secrets = append(*options.secrets, in.secrets...)
+	}
+
+	versionedCluster, err := decodeVersionedCluster(in.cluster, logger, opts...)
 	if err != nil {
-		return nil, errors.Wrap(err, "unable to read the given cluster configuration file")
+		return nil, err
+	}
+
+	if err = applyInventory(versionedCluster, in.inventory); err != nil {
+		return nil, err
+	}
+
+	if err = applyCredentialsExec(versionedCluster, options.credentialsExec); err != nil {
+		return nil, err
+	}
+
+	return defaultedKubeOneCluster(versionedCluster, in.tfOutput, in.credentialsFile)
+}
+
+// inputs are the KubeOneCluster manifest, inventory, Terraform output and credentials file
+type inputs struct {
+	cluster         []byte
+	inventory       *inventory.Inventory
+	tfOutput        []byte
+	credentialsFile []byte
+	// secrets are the values interpolated into the manifest
+	secrets []string
+}
+
+// readInputs reads the merged KubeOneCluster manifests, inventory, Terraform output and credentials file
+func readInputs(clusterCfgPaths []string, tfOutputPath, credentialsFilePath, inventoryPath string) (*inputs, error) {
+	in := &inputs{}
+
+	var err error
+	if in.cluster, in.secrets, err = readManifests(clusterCfgPaths); err != nil {
+		return nil, err
 	}
 
-	var tfOutput []byte
+	if len(inventoryPath) != 0 {
+		if in.inventory, err = inventory.Load(inventoryPath); err != nil {
+			return nil, err
+		}
+	}
 
 	switch {
 	case tfOutputPath == "-":
-		if tfOutput, err = ioutil.ReadAll(os.Stdin); err != nil {
+		if in.tfOutput, err = ioutil.ReadAll(os.Stdin); err != nil {
 			return nil, errors.Wrap(err, "unable to read terraform output from stdin")
 		}
 	case isDir(tfOutputPath):
+		// the local state is read directly, so the terraform binary is
+		// required only for the remote backends
+		if statePath, ok := terraform.LocalStatePath(tfOutputPath); ok {
+			if state, stateErr := ioutil.ReadFile(statePath); stateErr == nil && len(bytes.TrimSpace(state)) != 0 {
+				in.tfOutput = state
+				break
+			}
+		}
+
 		cmd := exec.Command("terraform", "output", "-json")
 		cmd.Dir = tfOutputPath
-		if tfOutput, err = cmd.Output(); err != nil {
+		if in.tfOutput, err = cmd.Output(); err != nil {
 			return nil, errors.Wrapf(err, "unable to read terraform output from the %q directory", tfOutputPath)
 		}
 	case len(tfOutputPath) != 0:
-		if tfOutput, err = ioutil.ReadFile(tfOutputPath); err != nil {
+		if in.tfOutput, err = ioutil.ReadFile(tfOutputPath); err != nil {
 			return nil, errors.Wrap(err, "unable to read the given terraform output file")
 		}
 	}
 
-	var credentialsFile []byte
+	if terraform.IsState(in.tfOutput) {
+		if in.tfOutput, err = terraform.OutputFromState(in.tfOutput); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(credentialsFilePath) != 0 {
-		credentialsFile, err = ioutil.ReadFile(credentialsFilePath)
-		if err != nil {
+		if in.credentialsFile, err = credentials.ReadFile(credentialsFilePath); err != nil {
 			return nil, errors.Wrap(err, "unable to read the given credentials file")
 		}
 	}
 
-	return BytesToKubeOneCluster(cluster, tfOutput, credentialsFile, logger)
+	return in, nil
 }
 
 // BytesToKubeOneCluster parses the bytes of the versioned KubeOneCluster manifests
-func BytesToKubeOneCluster(cluster, tfOutput, credentialsFile []byte, logger logrus.FieldLogger) (*kubeoneapi.KubeOneCluster, error) {
+func BytesToKubeOneCluster(cluster, tfOutput, credentialsFile []byte, logger logrus.FieldLogger, opts ...LoadOption) (*kubeoneapi.KubeOneCluster, error) {
+	versionedCluster, err := decodeVersionedCluster(cluster, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return defaultedKubeOneCluster(versionedCluster, tfOutput, credentialsFile)
+}
+
+// defaultedKubeOneCluster converts the versioned KubeOneCluster object to the
+// internal representation, see DefaultedV1Beta1KubeOneCluster
+func defaultedKubeOneCluster(versionedCluster runtime.Object, tfOutput, credentialsFile []byte) (*kubeoneapi.KubeOneCluster, error) {
+	switch versionedCluster := versionedCluster.(type) {
+	case *kubeonev1alpha1.KubeOneCluster:
+		return DefaultedV1Alpha1KubeOneCluster(versionedCluster, tfOutput, credentialsFile)
+	case *kubeonev1beta1.KubeOneCluster:
+		return DefaultedV1Beta1KubeOneCluster(versionedCluster, tfOutput, credentialsFile)
+	default:
+		return nil, errors.Errorf("unsupported object %T", versionedCluster)
+	}
+}
+
+// decodeVersionedCluster decodes the KubeOneCluster manifest into the
+// versioned KubeOneCluster object of its API version
+func decodeVersionedCluster(cluster []byte, logger logrus.FieldLogger, opts ...LoadOption) (runtime.Object, error) {
+	options := newLoadOptions(opts...)
+
 	// Get the GVK from the given KubeOneCluster manifest
 	typeMeta := runtime.TypeMeta{}
 	if err := yaml.Unmarshal(cluster, &typeMeta); err != nil {
@@ -116,37 +253,95 @@ func BytesToKubeOneCluster(cluster, tfOutput, credentialsFile []byte, logger log
 		logger.Warningf("The provided APIVersion %q is deprecated. Please use \"kubeone config migrate\" command to migrate to the latest version.", typeMeta.APIVersion)
 	}
 
+	var versionedCluster runtime.Object
+
 	// Parse the cluster bytes depending on the GVK
 	switch typeMeta.APIVersion {
 	case kubeonev1alpha1.SchemeGroupVersion.String():
-		v1alpha1Cluster := &kubeonev1alpha1.KubeOneCluster{}
-		if err := runtime.DecodeInto(kubeonescheme.Codecs.UniversalDecoder(), cluster, v1alpha1Cluster); err != nil {
-			return nil, err
-		}
-		return DefaultedV1Alpha1KubeOneCluster(v1alpha1Cluster, tfOutput, credentialsFile)
+		versionedCluster = &kubeonev1alpha1.KubeOneCluster{}
 	case kubeonev1beta1.SchemeGroupVersion.String():
-		v1beta1Cluster := &kubeonev1beta1.KubeOneCluster{}
-		if err := runtime.DecodeInto(kubeonescheme.Codecs.UniversalDecoder(), cluster, v1beta1Cluster); err != nil {
-			return nil, err
-		}
-		return DefaultedV1Beta1KubeOneCluster(v1beta1Cluster, tfOutput, credentialsFile)
+		versionedCluster = &kubeonev1beta1.KubeOneCluster{}
 	default:
 		return nil, errors.Errorf("invalid api version %q", typeMeta.APIVersion)
 	}
+
+	decoder := kubeonescheme.Codecs.UniversalDecoder()
+	if options.allowUnknownFields {
+		decoder = kubeonescheme.LenientCodecs.UniversalDecoder()
+	} else if err := checkUnknownFields(cluster, versionedCluster); err != nil {
+		return nil, err
+	}
+
+	if err := runtime.DecodeInto(decoder, cluster, versionedCluster); err != nil {
+		return nil, err
+	}
+
+	return versionedCluster, nil
 }
 
-// DefaultedV1Alpha1KubeOneCluster converts a v1alpha1 KubeOneCluster object to an internal representation of KubeOneCluster
-// object while sourcing information from Terraform output, applying default values and validating the KubeOneCluster
-// object
-func DefaultedV1Alpha1KubeOneCluster(versionedCluster *kubeonev1alpha1.KubeOneCluster, tfOutput, credentialsFile []byte) (*kubeoneapi.KubeOneCluster, error) {
-	if tfOutput != nil {
+// applyInventory sources the hosts from the Ansible inventory into the
+// versioned KubeOneCluster object
+func applyInventory(versionedCluster runtime.Object, inv *inventory.Inventory) error {
+	if inv == nil {
+		return nil
+	}
+
+	switch versionedCluster := versionedCluster.(type) {
+	case *kubeonev1beta1.KubeOneCluster:
+		return errors.Wrap(inv.Apply(versionedCluster), "failed to apply the inventory to the KubeOneCluster object")
+	default:
+		return errors.Errorf("the inventory is supported only with the %s manifests", kubeonev1beta1.SchemeGroupVersion)
+	}
+}
+
+// applyCredentialsExec overrides the credentials plugin of the versioned
+// KubeOneCluster object, if the command is configured, so it's defaulted and
+// validated like the plugin configured in the manifest
+func applyCredentialsExec(versionedCluster runtime.Object, command string) error {
+	if command == "" {
+		return nil
+	}
+
+	switch versionedCluster := versionedCluster.(type) {
+	case *kubeonev1beta1.KubeOneCluster:
+		versionedCluster.CloudProvider.CredentialsExec = &kubeonev1beta1.CredentialsExecConfig{Command: command}
+		return nil
+	default:
+		return errors.Errorf("the credentials plugin is supported only with the %s manifests", kubeonev1beta1.SchemeGroupVersion)
+	}
+}
+
+// applyTerraformOutput sources information from the Terraform output into
+// the versioned KubeOneCluster object
+func applyTerraformOutput(versionedCluster runtime.Object, tfOutput []byte) error {
+	if tfOutput == nil {
+		return nil
+	}
+
+	switch versionedCluster := versionedCluster.(type) {
+	case *kubeonev1alpha1.KubeOneCluster:
 		tfConfig, err := terraformv1alpha1.NewConfigFromJSON(tfOutput)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse Terraform config")
+			return errors.Wrap(err, "failed to parse Terraform config")
 		}
-		if err := tfConfig.Apply(versionedCluster); err != nil {
-			return nil, errors.Wrap(err, "failed to apply Terraform config to the KubeOneCluster object")
+		return errors.Wrap(tfConfig.Apply(versionedCluster), "failed to apply Terraform config to the KubeOneCluster object")
+	case *kubeonev1beta1.KubeOneCluster:
+		tfConfig, err := terraformv1beta1.NewConfigFromJSON(tfOutput)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse Terraform config")
 		}
+		return errors.Wrap(tfConfig.Apply(versionedCluster), "failed to apply Terraform config to the KubeOneCluster object")
+	default:
+		return errors.Errorf("unsupported object %T", versionedCluster)
+	}
+}
+
+// DefaultedV1Alpha1KubeOneCluster converts a v1alpha1 KubeOneCluster object to an internal representation of KubeOneCluster
+// object while sourcing information from Terraform output, applying default values and validating the KubeOneCluster
+// object
+func DefaultedV1Alpha1KubeOneCluster(versionedCluster *kubeonev1alpha1.KubeOneCluster, tfOutput, credentialsFile []byte) (*kubeoneapi.KubeOneCluster, error) {
+	if err := applyTerraformOutput(versionedCluster, tfOutput); err != nil {
+		return nil, err
 	}
 
 	internalCluster := &kubeoneapi.KubeOneCluster{}
@@ -174,14 +369,8 @@ func DefaultedV1Alpha1KubeOneCluster(versionedCluster *kubeonev1alpha1.KubeOneCl
 // object while sourcing information from Terraform output, applying default values and validating the KubeOneCluster
 // object
 func DefaultedV1Beta1KubeOneCluster(versionedCluster *kubeonev1beta1.KubeOneCluster, tfOutput, credentialsFile []byte) (*kubeoneapi.KubeOneCluster, error) {
-	if tfOutput != nil {
-		tfConfig, err := terraformv1beta1.NewConfigFromJSON(tfOutput)
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to parse Terraform config")
-		}
-		if err := tfConfig.Apply(versionedCluster); err != nil {
-			return nil, errors.Wrap(err, "failed to apply Terraform config to the KubeOneCluster object")
-		}
+	if err := applyTerraformOutput(versionedCluster, tfOutput); err != nil {
+		return nil, err
 	}
 
 	internalCluster := &kubeoneapi.KubeOneCluster{}
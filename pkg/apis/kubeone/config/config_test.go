@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	staleState    = `{"version": 4, "outputs": {"kubeone_api": {"value": {"endpoint": "stale.example.com"}}}}`
+	terraformJSON = `{"kubeone_api": {"value": {"endpoint": "lb.example.com"}}}`
+)
+
+func TestReadInputsTerraformDir(t *testing.T) {
+	tests := []struct {
+		name string
+		// backend is the content of .terraform/terraform.tfstate, which is
+		// not written if empty
+		backend          string
+		expectedEndpoint string
+	}{
+		{
+			name:             "not initialized",
+			expectedEndpoint: "stale.example.com",
+		},
+		{
+			name:             "local backend",
+			backend:          `{"version": 3, "backend": {"type": "local", "config": {}}}`,
+			expectedEndpoint: "stale.example.com",
+		},
+		{
+			name:             "stale local state with remote backend",
+			backend:          `{"version": 3, "backend": {"type": "s3", "config": {"bucket": "states"}}}`,
+			expectedEndpoint: "lb.example.com",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			// terraform prints the outputs read from the backend
+			binDir := filepath.Join(dir, "bin")
+			tfDir := filepath.Join(dir, "terraform")
+			manifestPath := filepath.Join(dir, "kubeone.yaml")
+			files := map[string]string{
+				filepath.Join(binDir, "terraform"):        "#!/bin/sh\necho '" + terraformJSON + "'\n",
+				filepath.Join(tfDir, "terraform.tfstate"): staleState,
+				manifestPath: "apiVersion: kubeone.io/v1beta1\nkind: KubeOneCluster\n",
+			}
+			if tc.backend != "" {
+				files[filepath.Join(tfDir, ".terraform", "terraform.tfstate")] = tc.backend
+			}
+			for path, content := range files {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(path, []byte(content), 0700); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			path := os.Getenv("PATH")
+			os.Setenv("PATH", binDir+string(os.PathListSeparator)+path)
+			defer os.Setenv("PATH", path)
+
+			in, err := readInputs([]string{manifestPath}, tfDir, "", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(string(in.tfOutput), tc.expectedEndpoint) {
+				t.Errorf("expected the terraform output with the endpoint %q, got %s", tc.expectedEndpoint, in.tfOutput)
+			}
+		})
+	}
+}
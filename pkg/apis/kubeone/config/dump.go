@@ -0,0 +1,417 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	kubeonescheme "k8c.io/kubeone/pkg/apis/kubeone/scheme"
+	kubeonev1beta1 "k8c.io/kubeone/pkg/apis/kubeone/v1beta1"
+	"k8c.io/kubeone/pkg/credentials"
+	"k8c.io/kubeone/pkg/redact"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// Sources of the values of the dumped KubeOneCluster manifest
+const (
+	SourceManifest  = "manifest"
+	SourceInventory = "inventory"
+	SourceTerraform = "terraform"
+	SourceFlag      = "flag"
+	SourceDefault   = "default"
+)
+
+// secretKeyRegexp matches the keys holding credentials, in the free-form
+// provider specs and among the interpolated fields
+var secretKeyRegexp = regexp.MustCompile(`(?i)(password|secret|token|accesskey|credential|apikey)`)
+
+// DumpKubeOneCluster returns the KubeOneCluster manifest, fully resolved from
+// the versioned KubeOneCluster manifest, inventory, flags, Terraform output and defaults, in the
+// latest API version. Every value is commented with its source, and the
+// credentials are redacted.
+func DumpKubeOneCluster(clusterCfgPaths []string, tfOutputPath, credentialsFilePath string, logger logrus.FieldLogger, opts ...LoadOption) ([]byte, error) {
+	options := newLoadOptions(opts...)
+
+	in, err := readInputs(clusterCfgPaths, tfOutputPath, credentialsFilePath, options.inventoryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := decodeVersionedCluster(in.cluster, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the inventory, the flags and the Terraform output are applied
+	// separately to find out the values sourced from them
+	inventoried := decoded.DeepCopyObject()
+	if err = applyInventory(inventoried, in.inventory); err != nil {
+		return nil, err
+	}
+
+	overridden := inventoried.DeepCopyObject()
+	if err = applyCredentialsExec(overridden, options.credentialsExec); err != nil {
+		return nil, err
+	}
+
+	internalCluster, err := defaultedKubeOneCluster(overridden.DeepCopyObject(), in.tfOutput, in.credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := overridden.DeepCopyObject()
+	if err = applyTerraformOutput(merged, in.tfOutput); err != nil {
+		return nil, err
+	}
+
+	d := &dumper{}
+
+	if d.resolved, err = latestVersionTree(internalCluster); err != nil {
+		return nil, err
+	}
+	if d.decoded, err = latestVersionTree(decoded); err != nil {
+		return nil, err
+	}
+	if d.inventoried, err = latestVersionTree(inventoried); err != nil {
+		return nil, err
+	}
+	if d.overridden, err = latestVersionTree(overridden); err != nil {
+		return nil, err
+	}
+	if d.merged, err = latestVersionTree(merged); err != nil {
+		return nil, err
+	}
+
+	// values are taken from the manifest as written if it's in the latest
+	// API version, otherwise from the converted manifest, where zero values
+	// can't be told apart from values not set
+	if _, ok := decoded.(*kubeonev1beta1.KubeOneCluster); ok {
+		if d.manifest, err = yamlTree(in.cluster); err != nil {
+			return nil, err
+		}
+	} else {
+		d.manifest = pruneZeroValues(d.decoded)
+	}
+
+	// credentials may be missing in the credentials file and the
+	// environment, when not required by the cloud provider
+	creds, _ := credentials.Any(internalCluster.CloudProvider, credentialsFilePath)
+	d.secrets = map[string]bool{}
+	for _, value := range creds {
+		if value != "" {
+			d.secrets[value] = true
+		}
+	}
+
+	// the interpolated values may be a part of the value, e.g. the password
+	// in the proxy URL, so they are replaced in the values as well
+	for _, value := range in.secrets {
+		d.secrets[value] = true
+	}
+	d.interpolated = redact.NewReplacer(in.secrets)
+
+	var buf bytes.Buffer
+	buf.WriteString("# The KubeOneCluster manifest resolved from the manifest, inventory, flags, Terraform output and defaults.\n")
+	buf.WriteString("# Values are commented with their source. Credentials are redacted.\n")
+	d.writeMap(&buf, d.resolved.(map[string]interface{}), nil, "")
+
+	return buf.Bytes(), nil
+}
+
+// latestVersionTree converts the KubeOneCluster object to the latest API
+// version, and returns its JSON representation as the tree of values
+func latestVersionTree(obj runtime.Object) (interface{}, error) {
+	versionedCluster, ok := obj.(*kubeonev1beta1.KubeOneCluster)
+	if !ok {
+		internalCluster, ok := obj.(*kubeoneapi.KubeOneCluster)
+		if !ok {
+			internalCluster = &kubeoneapi.KubeOneCluster{}
+			if err := kubeonescheme.Scheme.Convert(obj, internalCluster, nil); err != nil {
+				return nil, errors.Wrap(err, "failed to convert versioned cluster object to internal object")
+			}
+		}
+
+		versionedCluster = &kubeonev1beta1.KubeOneCluster{}
+		if err := kubeonescheme.Scheme.Convert(internalCluster, versionedCluster, nil); err != nil {
+			return nil, errors.Wrap(err, "failed to convert internal cluster object to versioned object")
+		}
+	}
+	versionedCluster.APIVersion = kubeonev1beta1.SchemeGroupVersion.String()
+	versionedCluster.Kind = KubeOneClusterKind
+
+	buf, err := json.Marshal(versionedCluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the KubeOneCluster object")
+	}
+
+	return jsonTree(buf)
+}
+
+func yamlTree(manifest []byte) (interface{}, error) {
+	buf, err := kyaml.YAMLToJSON(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert the KubeOneCluster manifest to JSON")
+	}
+
+	return jsonTree(buf)
+}
+
+// jsonTree decodes the JSON document keeping the numbers as written
+func jsonTree(buf []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the KubeOneCluster object")
+	}
+
+	return tree, nil
+}
+
+func pruneZeroValues(tree interface{}) interface{} {
+	switch tree := tree.(type) {
+	case map[string]interface{}:
+		pruned := map[string]interface{}{}
+		for key, value := range tree {
+			if value = pruneZeroValues(value); value != nil {
+				pruned[key] = value
+			}
+		}
+		if len(pruned) == 0 {
+			return nil
+		}
+		return pruned
+	case []interface{}:
+		if len(tree) == 0 {
+			return nil
+		}
+		pruned := make([]interface{}, len(tree))
+		for i, value := range tree {
+			pruned[i] = pruneZeroValues(value)
+		}
+		return pruned
+	case string, bool:
+		if reflect.ValueOf(tree).IsZero() {
+			return nil
+		}
+	case json.Number:
+		if f, err := tree.Float64(); err == nil && f == 0 {
+			return nil
+		}
+	}
+
+	return tree
+}
+
+// lookup returns the value at the path in the tree
+func lookup(tree interface{}, path []interface{}) (interface{}, bool) {
+	for _, elem := range path {
+		switch key := elem.(type) {
+		case string:
+			obj, ok := tree.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if tree, ok = obj[key]; !ok {
+				return nil, false
+			}
+		case int:
+			list, ok := tree.([]interface{})
+			if !ok || key >= len(list) {
+				return nil, false
+			}
+			tree = list[key]
+		}
+	}
+
+	return tree, tree != nil
+}
+
+type dumper struct {
+	// manifest, decoded, inventoried, merged and resolved are the trees of
+	// values of the KubeOneCluster as written, decoded, with the inventory
+	// hosts, merged with the Terraform output and fully resolved, respectively
+	manifest    interface{}
+	decoded     interface{}
+	inventoried interface{}
+	overridden  interface{}
+	merged      interface{}
+	resolved    interface{}
+	secrets     map[string]bool
+	// interpolated replaces the values interpolated into the manifest
+	interpolated *redact.Replacer
+}
+
+func (d *dumper) source(path []interface{}, value interface{}) string {
+	if manifestValue, ok := lookup(d.manifest, path); ok && reflect.DeepEqual(manifestValue, value) {
+		return SourceManifest
+	}
+
+	if sourcedFrom(d.merged, d.overridden, path, value) {
+		return SourceTerraform
+	}
+	if sourcedFrom(d.overridden, d.inventoried, path, value) {
+		return SourceFlag
+	}
+	if sourcedFrom(d.inventoried, d.decoded, path, value) {
+		return SourceInventory
+	}
+
+	return SourceDefault
+}
+
+// sourcedFrom returns whether the value was set or changed in the tree,
+// compared to the tree it was applied to
+func sourcedFrom(tree, base interface{}, path []interface{}, value interface{}) bool {
+	treeValue, ok := lookup(tree, path)
+	if !ok || !reflect.DeepEqual(treeValue, value) {
+		return false
+	}
+
+	baseValue, ok := lookup(base, path)
+	return !ok || !reflect.DeepEqual(baseValue, treeValue)
+}
+
+func (d *dumper) redacted(path []interface{}, value interface{}) bool {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return false
+	}
+
+	if d.secrets[str] {
+		return true
+	}
+
+	if len(path) == 2 && path[0] == "cloudProvider" && path[1] == "cloudConfig" {
+		return true
+	}
+
+	// the provider specs are free-form, so credentials are recognized by keys
+	for i, elem := range path {
+		if elem == "cloudProviderSpec" || elem == "operatingSystemSpec" {
+			for _, key := range path[i+1:] {
+				if key, ok := key.(string); ok && secretKeyRegexp.MatchString(key) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (d *dumper) writeMap(buf *bytes.Buffer, obj map[string]interface{}, path []interface{}, indent string) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		d.writeValue(buf, indent+yamlScalar(key)+":", obj[key], append(path[:len(path):len(path)], key), indent)
+	}
+}
+
+// writeValue writes the value after the prefix, which is either the map key
+// or the list item indicator
+func (d *dumper) writeValue(buf *bytes.Buffer, prefix string, value interface{}, path []interface{}, indent string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) > 0 {
+			buf.WriteString(prefix + "\n")
+			d.writeMap(buf, value, path, indent+"  ")
+			return
+		}
+	case []interface{}:
+		if len(value) > 0 {
+			buf.WriteString(prefix + "\n")
+			for i, item := range value {
+				d.writeItem(buf, item, append(path[:len(path):len(path)], i), indent)
+			}
+			return
+		}
+	}
+
+	d.writeScalar(buf, prefix, value, path, indent)
+}
+
+func (d *dumper) writeItem(buf *bytes.Buffer, item interface{}, path []interface{}, indent string) {
+	obj, ok := item.(map[string]interface{})
+	if !ok || len(obj) == 0 {
+		d.writeValue(buf, indent+"-", item, path, indent+"  ")
+		return
+	}
+
+	// the first key of the map is written on the line of the list item indicator
+	var itemBuf bytes.Buffer
+	d.writeMap(&itemBuf, obj, path, indent+"  ")
+	buf.WriteString(indent + "- " + strings.TrimPrefix(itemBuf.String(), indent+"  "))
+}
+
+func (d *dumper) writeScalar(buf *bytes.Buffer, prefix string, value interface{}, path []interface{}, indent string) {
+	comment := " # " + d.source(path, value)
+
+	if d.redacted(path, value) {
+		value = redact.Redacted
+	} else if str, ok := value.(string); ok {
+		value = d.interpolated.Replace(str)
+	}
+
+	lines := strings.Split(yamlScalar(value), "\n")
+	buf.WriteString(prefix + " " + lines[0] + comment + "\n")
+	// block scalars continue on the following lines
+	for _, line := range lines[1:] {
+		buf.WriteString(indent + "  " + strings.TrimPrefix(line, "  ") + "\n")
+	}
+}
+
+func yamlScalar(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	case map[string]interface{}:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+
+	return strings.TrimSuffix(string(out), "\n")
+}
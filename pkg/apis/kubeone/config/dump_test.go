@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const dumpManifest = `
+apiVersion: kubeone.io/v1beta1
+kind: KubeOneCluster
+name: demo
+versions:
+  kubernetes: 1.20.2
+cloudProvider:
+  hetzner: {}
+  cloudConfig: |
+    token: hunter2
+clusterNetwork:
+  podSubnet: 10.244.0.0/16
+dynamicWorkers:
+- name: pool1
+  replicas: 2
+  providerSpec:
+    operatingSystem: ubuntu
+    cloudProviderSpec:
+      serverType: cx21
+      token: hunter2
+`
+
+const dumpTerraformOutput = `{
+  "kubeone_api": {"value": {"endpoint": "lb.example.com"}},
+  "kubeone_hosts": {"value": {"control_plane": {
+    "cluster_name": "demo",
+    "public_address": ["1.1.1.1"],
+    "private_address": ["10.0.0.1"],
+    "ssh_private_key_file": "/dev/null"
+  }}}
+}`
+
+const dumpInventory = `
+[kube_workers]
+worker1 ansible_host=203.0.113.5 ansible_user=ubuntu
+`
+
+func TestDumpKubeOneCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifestPath := filepath.Join(dir, "kubeone.yaml")
+	tfOutputPath := filepath.Join(dir, "tf.json")
+	inventoryPath := filepath.Join(dir, "inventory.ini")
+	for path, content := range map[string]string{manifestPath: dumpManifest, tfOutputPath: dumpTerraformOutput, inventoryPath: dumpInventory} {
+		if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := DumpKubeOneCluster([]string{manifestPath}, tfOutputPath, "", logrus.New(), Inventory(inventoryPath), CredentialsExec("/bin/true"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dump := string(out)
+
+	expectedLines := []string{
+		"name: demo # manifest",
+		"  podSubnet: 10.244.0.0/16 # manifest",
+		"  serviceSubnet: 10.96.0.0/12 # default",
+		"  host: lb.example.com # terraform",
+		"  port: 6443 # default",
+		"    privateAddress: 10.0.0.1 # terraform",
+		"    publicAddress: 203.0.113.5 # inventory",
+		"    sshUsername: ubuntu # inventory",
+		"  cloudConfig: REDACTED # manifest",
+		"      token: REDACTED # manifest",
+		"      serverType: cx21 # manifest",
+		"    command: /bin/true # flag",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(dump, line+"\n") {
+			t.Errorf("expected line %q in the dump:\n%s", line, dump)
+		}
+	}
+
+	if strings.Contains(dump, "hunter2") {
+		t.Errorf("expected credentials to be redacted in the dump:\n%s", dump)
+	}
+}
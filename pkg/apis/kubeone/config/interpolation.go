@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// referenceRegexp matches the references interpolated in the manifest string
+// values, ${env:NAME} and ${file:path}. References prefixed with another $ are
+// escaped, and written as they are without the leading $.
+var referenceRegexp = regexp.MustCompile(`\$?\$\{(env|file):([^}]*)\}`)
+
+// hasReferences returns whether the manifest contains references, either
+// interpolated or escaped
+func hasReferences(manifest []byte) bool {
+	return referenceRegexp.Match(manifest)
+}
+
+// interpolator resolves the references in the manifest string values. The
+// values resolved into the fields holding credentials are collected as secrets.
+type interpolator struct {
+	// dir is the directory of the manifest, the file paths are relative to
+	dir     string
+	secrets map[string]bool
+	errs    []string
+}
+
+// interpolate returns the tree of values with references resolved
+func (i *interpolator) interpolate(tree interface{}, path string) interface{} {
+	switch tree := tree.(type) {
+	case map[string]interface{}:
+		for key, value := range tree {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			tree[key] = i.interpolate(value, childPath)
+		}
+	case []interface{}:
+		for idx, value := range tree {
+			tree[idx] = i.interpolate(value, fmt.Sprintf("%s[%d]", path, idx))
+		}
+	case string:
+		return referenceRegexp.ReplaceAllStringFunc(tree, func(ref string) string {
+			if strings.HasPrefix(ref, "$$") {
+				return ref[1:]
+			}
+
+			value, err := i.resolve(ref)
+			if err != nil {
+				i.errs = append(i.errs, fmt.Sprintf("%s: %s: %v", path, ref, err))
+				return ref
+			}
+			if value != "" && sensitiveField(path) {
+				i.secrets[value] = true
+			}
+
+			return value
+		})
+	}
+
+	return tree
+}
+
+// sensitiveField returns whether the field at the path holds credentials: the
+// cloud-config, the proxy URLs, or fields named like credentials
+func sensitiveField(path string) bool {
+	switch path {
+	case "cloudProvider.cloudConfig", "proxy.http", "proxy.https":
+		return true
+	}
+
+	key := path[strings.LastIndex(path, ".")+1:]
+	if idx := strings.Index(key, "["); idx >= 0 {
+		key = key[:idx]
+	}
+
+	return secretKeyRegexp.MatchString(key)
+}
+
+func (i *interpolator) resolve(ref string) (string, error) {
+	groups := referenceRegexp.FindStringSubmatch(ref)
+	kind, name := groups[1], groups[2]
+	if name == "" {
+		return "", errors.New("reference name is empty")
+	}
+
+	switch kind {
+	case "env":
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.New("environment variable is not set")
+		}
+		return value, nil
+	default:
+		// the path is relative to the manifest, like the other file paths
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(i.dir, name)
+		}
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return "", errors.Wrap(err, "unable to read file")
+		}
+		// trailing newlines are dropped, like in the shell command substitution
+		return strings.TrimRight(string(content), "\n"), nil
+	}
+}
+
+// err returns the error listing all unresolved references in the manifest
+func (i *interpolator) err(manifestPath string) error {
+	if len(i.errs) == 0 {
+		return nil
+	}
+
+	sort.Strings(i.errs)
+
+	return errors.Errorf("unresolved references in the cluster configuration file %q: %s", manifestPath, strings.Join(i.errs, ", "))
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kyaml "sigs.k8s.io/yaml"
+)
+
+// basesKey is the key of the manifest listing the manifests it's layered on top
+// of. The paths are relative to the directory of the manifest.
+const basesKey = "bases"
+
+// keyedLists are the lists of hosts which are merged by the host address,
+// instead of being replaced
+var keyedLists = map[string]bool{
+	"controlPlane.hosts":  true,
+	"staticWorkers.hosts": true,
+}
+
+// relativePaths are the paths of the fields holding paths, which are relative
+// to the directory of the manifest
+var relativePaths = [][]string{
+	{"addons", "path"},
+	{"features", "staticAuditLog", "config", "policyFilePath"},
+	{"features", "podNodeSelector", "config", "configFilePath"},
+}
+
+// readManifests reads the KubeOneCluster manifests, including their bases, and
+// merges them in the order they are given, each on top of the previous ones:
+//   - maps are merged recursively, and a null value removes the key,
+//   - hosts are merged by publicAddress, privateAddress if the publicAddress is
+//     not set, or by their index if neither is set; other hosts are appended,
+//   - other lists and values are replaced.
+//
+// The relative paths in the manifests are resolved against the directory of
+// the manifest declaring them, so they're kept working after merging.
+//
+// The references in the manifests are interpolated before merging, and the
+// values interpolated into the fields holding credentials are returned as
+// secrets.
+func readManifests(paths []string) ([]byte, []string, error) {
+	if len(paths) == 0 || (len(paths) == 1 && len(paths[0]) == 0) {
+		return nil, nil, errors.New("cluster configuration path not provided")
+	}
+
+	if len(paths) == 1 {
+		manifest, err := ioutil.ReadFile(paths[0])
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "unable to read the given cluster configuration file")
+		}
+
+		// a single manifest is used as it is, unless it's layered or
+		// interpolated
+		doc, err := yamlTree(manifest)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "unable to parse the cluster configuration file %q", paths[0])
+		}
+		if obj, ok := doc.(map[string]interface{}); (!ok || obj[basesKey] == nil) && !hasReferences(manifest) {
+			return manifest, nil, nil
+		}
+	}
+
+	l := &layers{secrets: map[string]bool{}}
+	for _, path := range paths {
+		if err := l.add(path, nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	merged, err := json.Marshal(l.merged)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal the merged cluster configuration")
+	}
+
+	manifest, err := kyaml.JSONToYAML(merged)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to convert the merged cluster configuration to YAML")
+	}
+
+	secrets := make([]string, 0, len(l.secrets))
+	for secret := range l.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Strings(secrets)
+
+	return manifest, secrets, nil
+}
+
+type layers struct {
+	merged     map[string]interface{}
+	secrets    map[string]bool
+	apiVersion string
+	// apiVersionPath is the manifest defining the apiVersion
+	apiVersionPath string
+}
+
+// add merges the bases of the manifest, followed by the manifest itself.
+// parents are the manifests layered on top of the manifest, used to detect
+// the cycles.
+func (l *layers) add(path string, parents []string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrapf(err, "unable to resolve the cluster configuration path %q", path)
+	}
+	for _, parent := range parents {
+		if parent == absPath {
+			return errors.Errorf("cluster configuration %q is its own base: %s", path, strings.Join(append(parents, absPath), " -> "))
+		}
+	}
+
+	manifest, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read the cluster configuration file %q", path)
+	}
+
+	tree, err := yamlTree(manifest)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse the cluster configuration file %q", path)
+	}
+	doc, ok := tree.(map[string]interface{})
+	if !ok {
+		return errors.Errorf("cluster configuration file %q is not a YAML object", path)
+	}
+
+	if bases, ok := doc[basesKey]; ok {
+		delete(doc, basesKey)
+
+		list, ok := bases.([]interface{})
+		if !ok {
+			return errors.Errorf("%s in the cluster configuration file %q must be a list of paths", basesKey, path)
+		}
+		for _, base := range list {
+			basePath, ok := base.(string)
+			if !ok || basePath == "" {
+				return errors.Errorf("%s in the cluster configuration file %q must be a list of paths", basesKey, path)
+			}
+			if !filepath.IsAbs(basePath) {
+				basePath = filepath.Join(filepath.Dir(path), basePath)
+			}
+			if err = l.add(basePath, append(parents, absPath)); err != nil {
+				return err
+			}
+		}
+	}
+
+	in := &interpolator{dir: filepath.Dir(path), secrets: l.secrets}
+	in.interpolate(doc, "")
+	if err = in.err(path); err != nil {
+		return err
+	}
+
+	resolvePaths(doc, filepath.Dir(absPath))
+
+	if apiVersion, ok := doc["apiVersion"].(string); ok {
+		if l.apiVersion != "" && l.apiVersion != apiVersion {
+			return errors.Errorf("cluster configuration %q has apiVersion %q, but %q has %q", path, apiVersion, l.apiVersionPath, l.apiVersion)
+		}
+		l.apiVersion = apiVersion
+		l.apiVersionPath = path
+	}
+
+	merged, _ := mergeValues(l.merged, doc, "").(map[string]interface{})
+	l.merged = merged
+
+	return nil
+}
+
+// resolvePaths makes the relative paths in the manifest absolute, by joining
+// them with the directory of the manifest
+func resolvePaths(doc map[string]interface{}, dir string) {
+	for _, fieldPath := range relativePaths {
+		obj := doc
+		for _, key := range fieldPath[:len(fieldPath)-1] {
+			obj, _ = obj[key].(map[string]interface{})
+		}
+
+		key := fieldPath[len(fieldPath)-1]
+		if path, ok := obj[key].(string); ok && path != "" && !filepath.IsAbs(path) {
+			obj[key] = filepath.Join(dir, path)
+		}
+	}
+}
+
+// mergeValues merges the overlay on top of the base value at the path
+func mergeValues(base, overlay interface{}, path string) interface{} {
+	switch overlay := overlay.(type) {
+	case map[string]interface{}:
+		baseObj, ok := base.(map[string]interface{})
+		if !ok {
+			baseObj = map[string]interface{}{}
+		}
+
+		merged := make(map[string]interface{}, len(baseObj))
+		for key, value := range baseObj {
+			merged[key] = value
+		}
+		for key, value := range overlay {
+			if value == nil {
+				delete(merged, key)
+				continue
+			}
+
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			merged[key] = mergeValues(merged[key], value, childPath)
+		}
+
+		return merged
+	case []interface{}:
+		baseList, ok := base.([]interface{})
+		if !ok || !keyedLists[path] {
+			return overlay
+		}
+
+		return mergeHosts(baseList, overlay, path)
+	default:
+		return overlay
+	}
+}
+
+func mergeHosts(base, overlay []interface{}, path string) []interface{} {
+	merged := append([]interface{}{}, base...)
+
+	for i, host := range overlay {
+		idx := -1
+		if address := hostAddress(host); address != "" {
+			for j, baseHost := range merged {
+				if hostAddress(baseHost) == address {
+					idx = j
+					break
+				}
+			}
+		} else if i < len(merged) {
+			idx = i
+		}
+
+		if idx < 0 {
+			merged = append(merged, host)
+			continue
+		}
+		merged[idx] = mergeValues(merged[idx], host, path)
+	}
+
+	return merged
+}
+
+func hostAddress(host interface{}) string {
+	obj, ok := host.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	if address, ok := obj["publicAddress"].(string); ok && address != "" {
+		return address
+	}
+	address, _ := obj["privateAddress"].(string)
+
+	return address
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"sigs.k8s.io/yaml"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkUnknownFields returns an error listing paths of all fields in the
+// manifest which are not known to the versioned object
+func checkUnknownFields(manifest []byte, obj interface{}) error {
+	var data interface{}
+	if err := yaml.Unmarshal(manifest, &data); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the KubeOneCluster manifest")
+	}
+
+	unknown := unknownFields(data, reflect.TypeOf(obj), "")
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return errors.Errorf("unknown fields in the KubeOneCluster manifest: %s", strings.Join(unknown, ", "))
+}
+
+func unknownFields(data interface{}, t reflect.Type, path string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// types decoding themselves, such as json.RawMessage, accept any fields
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	unknown := []string{}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		fields := map[string]reflect.Type{}
+		structFields(t, fields)
+
+		for key, value := range obj {
+			fieldPath := key
+			if path != "" {
+				fieldPath = path + "." + key
+			}
+
+			fieldType, ok := fields[key]
+			if !ok {
+				unknown = append(unknown, fieldPath)
+				continue
+			}
+			unknown = append(unknown, unknownFields(value, fieldType, fieldPath)...)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := data.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		for i, item := range items {
+			unknown = append(unknown, unknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		for key, value := range obj {
+			unknown = append(unknown, unknownFields(value, t.Elem(), fmt.Sprintf("%s[%s]", path, key))...)
+		}
+	}
+
+	return unknown
+}
+
+// structFields collects the JSON names and types of the struct fields,
+// including fields of the inlined structs
+func structFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" && (field.Anonymous || strings.Contains(tag, ",inline")) {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				structFields(fieldType, fields)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+}
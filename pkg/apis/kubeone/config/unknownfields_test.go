@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testManifest = `
+apiVersion: kubeone.io/v1beta1
+kind: KubeOneCluster
+name: test
+versions:
+  kubernetes: 1.20.2
+cloudProvider:
+  none: {}
+controlPlane:
+  hosts:
+  - publicAddress: 10.0.0.1
+    privateAddress: 10.0.0.1
+    sshPrivateKeyFile: /dev/null
+apiEndpoint:
+  host: 10.0.0.1
+`
+
+func TestBytesToKubeOneClusterUnknownFields(t *testing.T) {
+	testcases := []struct {
+		name               string
+		extra              string
+		allowUnknownFields bool
+		expectedErr        string
+	}{
+		{
+			name: "known fields",
+			extra: `
+clusterNetwork:
+  podSubnet: 10.244.0.0/16
+dynamicWorkers:
+- name: workers
+  replicas: 1
+  providerSpec:
+    cloudProviderSpec:
+      anyField: value
+    operatingSystem: ubuntu
+    labels:
+      anyLabel: value
+`,
+		},
+		{
+			name: "unknown nested fields",
+			extra: `
+clusterNetwork:
+  podSubnets: 10.244.0.0/16
+  serviceSubnet: 10.96.0.0/12
+`,
+			expectedErr: "unknown fields in the KubeOneCluster manifest: clusterNetwork.podSubnets",
+		},
+		{
+			name: "unknown fields in list items",
+			extra: `
+staticWorkers:
+  hosts:
+  - publicAddress: 10.0.0.2
+    privateAddress: 10.0.0.2
+    sshPrt: 22
+unknown: true
+`,
+			expectedErr: "unknown fields in the KubeOneCluster manifest: staticWorkers.hosts[0].sshPrt, unknown",
+		},
+		{
+			name: "unknown fields allowed",
+			extra: `
+clusterNetwork:
+  podSubnets: 10.244.0.0/16
+`,
+			allowUnknownFields: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest := []byte(testManifest + strings.TrimPrefix(tc.extra, "\n"))
+
+			_, err := BytesToKubeOneCluster(manifest, nil, nil, logrus.New(), AllowUnknownFields(tc.allowUnknownFields))
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("expected no error, but got %v", err)
+			}
+			if tc.expectedErr != "" && (err == nil || err.Error() != tc.expectedErr) {
+				t.Fatalf("expected error %q, but got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
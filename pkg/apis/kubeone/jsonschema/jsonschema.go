@@ -0,0 +1,262 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonschema generates the JSON Schema of the KubeOneCluster manifest
+// from the versioned API types, to be used by editors for validation and
+// completion of the manifests.
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubeonescheme "k8c.io/kubeone/pkg/apis/kubeone/scheme"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Draft is the JSON Schema version of the generated schemas
+	Draft = "http://json-schema.org/draft-07/schema#"
+
+	kubeOneClusterKind = "KubeOneCluster"
+)
+
+// Schema is a JSON Schema, limited to the keywords used for the KubeOneCluster manifest
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// swaggerDoc is implemented by the API types documenting their fields
+type swaggerDoc interface {
+	SwaggerDoc() map[string]string
+}
+
+var (
+	swaggerDocType      = reflect.TypeOf((*swaggerDoc)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(metav1.Time{})
+)
+
+// Generate returns the JSON Schema of the KubeOneCluster manifest in the given API version
+func Generate(apiVersion string) (*Schema, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid api version %q", apiVersion)
+	}
+
+	opts, ok := versions[gv]
+	if !ok {
+		return nil, errors.Errorf("api version %q is not supported", apiVersion)
+	}
+
+	obj, err := kubeonescheme.Scheme.New(gv.WithKind(kubeOneClusterKind))
+	if err != nil {
+		return nil, errors.Wrapf(err, "api version %q is not supported", apiVersion)
+	}
+
+	g := &generator{
+		opts:        opts,
+		definitions: map[string]*Schema{},
+	}
+
+	root := &Schema{
+		Schema: Draft,
+		Title:  kubeOneClusterKind + " " + gv.String(),
+		AllOf:  []*Schema{g.typeSchema(reflect.TypeOf(obj))},
+	}
+	root.Definitions = g.definitions
+
+	cluster := g.definitions[kubeOneClusterKind]
+	cluster.Properties["apiVersion"].Enum = []interface{}{gv.String()}
+	cluster.Properties["kind"].Enum = []interface{}{kubeOneClusterKind}
+	// bases are not a field of the API types, they're read and removed
+	// before the manifest is decoded
+	cluster.Properties["bases"] = &Schema{
+		Description: "Bases are the paths of the manifests this manifest is layered on top of, relative to the directory of this manifest.",
+		Type:        "array",
+		Items:       &Schema{Type: "string"},
+	}
+
+	return root, nil
+}
+
+type generator struct {
+	opts        versionOptions
+	definitions map[string]*Schema
+}
+
+// typeSchema returns the schema of the type, referencing the definitions of
+// the struct types
+func (g *generator) typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if values, ok := g.opts.enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() != reflect.Struct && reflect.PtrTo(t).Implements(jsonUnmarshalerType):
+		// types decoding themselves, such as json.RawMessage, accept any value
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as the base64 string
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		name := g.definitionName(t)
+		if _, ok := g.definitions[name]; !ok {
+			// the definition is registered before it's built to stop
+			// the recursion on the recursive types
+			g.definitions[name] = &Schema{}
+			*g.definitions[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/definitions/" + name}
+	}
+
+	return &Schema{}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+
+	docs := typeDocs(t)
+	s.Description = docs[""]
+
+	g.addFields(s, t)
+
+	s.Required = append(s.Required, g.opts.required[t]...)
+	sort.Strings(s.Required)
+
+	if g.opts.exclusive[t] {
+		one := 1
+		s.MaxProperties = &one
+	}
+
+	return s
+}
+
+// addFields adds the properties of the struct fields, including fields of the
+// inlined structs
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	docs := typeDocs(t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if name == "" && (field.Anonymous || strings.Contains(tag, ",inline")) {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(s, fieldType)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		var prop *Schema
+		if values, ok := g.opts.fieldEnums[fieldKey{t, name}]; ok {
+			prop = &Schema{Type: "string", Enum: values}
+		} else {
+			prop = g.typeSchema(field.Type)
+		}
+
+		if doc := docs[name]; doc != "" {
+			if prop.Ref != "" {
+				// keywords next to $ref are ignored, so the referenced
+				// schema is wrapped to keep the field description
+				prop = &Schema{AllOf: []*Schema{prop}}
+			}
+			prop.Description = doc
+		}
+
+		s.Properties[name] = prop
+	}
+}
+
+// definitionName returns the name of the struct type definition. The API types
+// are named by their type name, and others are qualified by their package.
+func (g *generator) definitionName(t reflect.Type) string {
+	if t.PkgPath() == g.opts.pkgPath {
+		return t.Name()
+	}
+
+	return strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + t.Name()
+}
+
+func typeDocs(t reflect.Type) map[string]string {
+	if !t.Implements(swaggerDocType) {
+		return nil
+	}
+
+	return reflect.Zero(t).Interface().(swaggerDoc).SwaggerDoc()
+}
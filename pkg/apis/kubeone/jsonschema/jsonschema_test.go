@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	testcases := []struct {
+		name             string
+		apiVersion       string
+		definition       string
+		property         string
+		expectedEnum     []interface{}
+		expectedRequired []string
+		expectedErr      bool
+	}{
+		{
+			name:             "v1beta1 cluster",
+			apiVersion:       "kubeone.io/v1beta1",
+			definition:       "KubeOneCluster",
+			property:         "kind",
+			expectedEnum:     []interface{}{"KubeOneCluster"},
+			expectedRequired: []string{"apiVersion", "cloudProvider", "kind", "name", "versions"},
+		},
+		{
+			name:       "v1beta1 bases",
+			apiVersion: "kubeone.io/v1beta1",
+			definition: "KubeOneCluster",
+			property:   "bases",
+		},
+		{
+			name:         "v1beta1 host enum",
+			apiVersion:   "kubeone.io/v1beta1",
+			definition:   "HostConfig",
+			property:     "connection",
+			expectedEnum: []interface{}{"ssh", "local"},
+		},
+		{
+			name:             "v1beta1 operating system enum",
+			apiVersion:       "kubeone.io/v1beta1",
+			definition:       "ProviderSpec",
+			property:         "operatingSystem",
+			expectedEnum:     machineControllerOperatingSystems,
+			expectedRequired: []string{"cloudProviderSpec", "operatingSystem"},
+		},
+		{
+			name:         "v1alpha1 CNI enum",
+			apiVersion:   "kubeone.io/v1alpha1",
+			definition:   "CNI",
+			property:     "provider",
+			expectedEnum: []interface{}{"canal", "weave-net", "external"},
+		},
+		{
+			name:             "v1alpha1 api version",
+			apiVersion:       "kubeone.io/v1alpha1",
+			definition:       "KubeOneCluster",
+			property:         "apiVersion",
+			expectedEnum:     []interface{}{"kubeone.io/v1alpha1"},
+			expectedRequired: []string{"apiVersion", "kind", "name"},
+		},
+		{
+			name:        "unsupported api version",
+			apiVersion:  "kubeone.io/v1",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Generate(tc.apiVersion)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			def, ok := s.Definitions[tc.definition]
+			if !ok {
+				t.Fatalf("definition %q not found", tc.definition)
+			}
+			if def.AdditionalProperties != false {
+				t.Errorf("expected additional properties of %q to be disallowed", tc.definition)
+			}
+
+			prop, ok := def.Properties[tc.property]
+			if !ok {
+				t.Fatalf("property %q of %q not found", tc.property, tc.definition)
+			}
+			if prop.Description == "" {
+				t.Errorf("expected description of %q", tc.property)
+			}
+			if !reflect.DeepEqual(prop.Enum, tc.expectedEnum) {
+				t.Errorf("expected enum %v, but got %v", tc.expectedEnum, prop.Enum)
+			}
+			if tc.expectedRequired != nil && !reflect.DeepEqual(def.Required, tc.expectedRequired) {
+				t.Errorf("expected required %v, but got %v", tc.expectedRequired, def.Required)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jsonschema
+
+import (
+	"reflect"
+
+	providerconfigtypes "github.com/kubermatic/machine-controller/pkg/providerconfig/types"
+
+	kubeonev1alpha1 "k8c.io/kubeone/pkg/apis/kubeone/v1alpha1"
+	kubeonev1beta1 "k8c.io/kubeone/pkg/apis/kubeone/v1beta1"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// versionOptions holds the constraints of the API version which can't be
+// derived from the Go types
+type versionOptions struct {
+	pkgPath string
+	// enums are the allowed values of the types
+	enums map[reflect.Type][]interface{}
+	// fieldEnums are the allowed values of the fields of plain types
+	fieldEnums map[fieldKey][]interface{}
+	// required are the fields which must be set in the manifest. Fields
+	// sourced from the Terraform output or defaulted are not required.
+	required map[reflect.Type][]string
+	// exclusive are the types with at most one field set
+	exclusive map[reflect.Type]bool
+}
+
+type fieldKey struct {
+	t    reflect.Type
+	name string
+}
+
+// machineControllerOperatingSystems are the operating systems supported by
+// machine-controller for the dynamic workers
+var machineControllerOperatingSystems = []interface{}{
+	string(providerconfigtypes.OperatingSystemCoreos),
+	string(providerconfigtypes.OperatingSystemUbuntu),
+	string(providerconfigtypes.OperatingSystemCentOS),
+	string(providerconfigtypes.OperatingSystemSLES),
+	string(providerconfigtypes.OperatingSystemRHEL),
+	string(providerconfigtypes.OperatingSystemFlatcar),
+}
+
+var versions = map[schema.GroupVersion]versionOptions{
+	kubeonev1alpha1.SchemeGroupVersion: {
+		pkgPath: reflect.TypeOf(kubeonev1alpha1.KubeOneCluster{}).PkgPath(),
+		enums: map[reflect.Type][]interface{}{
+			reflect.TypeOf(kubeonev1alpha1.CNIProvider("")): {
+				string(kubeonev1alpha1.CNIProviderCanal),
+				string(kubeonev1alpha1.CNIProviderWeaveNet),
+				string(kubeonev1alpha1.CNIProviderExternal),
+			},
+			reflect.TypeOf(kubeonev1alpha1.CloudProviderName("")): {
+				string(kubeonev1alpha1.CloudProviderNameAWS),
+				string(kubeonev1alpha1.CloudProviderNameAzure),
+				string(kubeonev1alpha1.CloudProviderNameOpenStack),
+				string(kubeonev1alpha1.CloudProviderNameHetzner),
+				string(kubeonev1alpha1.CloudProviderNameDigitalOcean),
+				string(kubeonev1alpha1.CloudProviderNamePacket),
+				string(kubeonev1alpha1.CloudProviderNameVSphere),
+				string(kubeonev1alpha1.CloudProviderNameGCE),
+				string(kubeonev1alpha1.CloudProviderNameNone),
+			},
+		},
+		fieldEnums: map[fieldKey][]interface{}{
+			{reflect.TypeOf(kubeonev1alpha1.ProviderSpec{}), "operatingSystem"}: machineControllerOperatingSystems,
+		},
+		required: map[reflect.Type][]string{
+			reflect.TypeOf(kubeonev1alpha1.KubeOneCluster{}):        {"apiVersion", "kind", "name"},
+			reflect.TypeOf(kubeonev1alpha1.CloudProviderSpec{}):     {"name"},
+			reflect.TypeOf(kubeonev1alpha1.VersionConfig{}):         {"kubernetes"},
+			reflect.TypeOf(kubeonev1alpha1.WorkerConfig{}):          {"name", "replicas", "providerSpec"},
+			reflect.TypeOf(kubeonev1alpha1.ProviderSpec{}):          {"cloudProviderSpec", "operatingSystem"},
+			reflect.TypeOf(kubeonev1alpha1.NetworkConfig{}):         {"cidr", "gateway", "dns"},
+			reflect.TypeOf(kubeonev1alpha1.DNSConfig{}):             {"servers"},
+			reflect.TypeOf(kubeonev1alpha1.PodNodeSelectorConfig{}): {"configFilePath"},
+			reflect.TypeOf(kubeonev1alpha1.StaticAuditLogConfig{}):  {"policyFilePath"},
+			reflect.TypeOf(kubeonev1alpha1.OpenIDConnectConfig{}):   {"issuerUrl", "clientId"},
+		},
+	},
+	kubeonev1beta1.SchemeGroupVersion: {
+		pkgPath: reflect.TypeOf(kubeonev1beta1.KubeOneCluster{}).PkgPath(),
+		enums: map[reflect.Type][]interface{}{
+			reflect.TypeOf(kubeonev1beta1.HostConnectionType("")): {
+				string(kubeonev1beta1.HostConnectionSSH),
+				string(kubeonev1beta1.HostConnectionLocal),
+			},
+			reflect.TypeOf(kubeonev1beta1.SSHHostKeyCheck("")): {
+				string(kubeonev1beta1.SSHHostKeyCheckIgnore),
+				string(kubeonev1beta1.SSHHostKeyCheckStrict),
+				string(kubeonev1beta1.SSHHostKeyCheckTrustOnFirstUse),
+			},
+			reflect.TypeOf(kubeonev1beta1.SSHFileTransfer("")): {
+				string(kubeonev1beta1.SSHFileTransferAuto),
+				string(kubeonev1beta1.SSHFileTransferSFTP),
+				string(kubeonev1beta1.SSHFileTransferExec),
+			},
+			reflect.TypeOf(kubeonev1beta1.PrivilegeEscalation("")): {
+				string(kubeonev1beta1.PrivilegeEscalationNone),
+				string(kubeonev1beta1.PrivilegeEscalationSudo),
+				string(kubeonev1beta1.PrivilegeEscalationSudoPassword),
+				string(kubeonev1beta1.PrivilegeEscalationDoas),
+			},
+		},
+		fieldEnums: map[fieldKey][]interface{}{
+			{reflect.TypeOf(kubeonev1beta1.ProviderSpec{}), "operatingSystem"}: machineControllerOperatingSystems,
+		},
+		required: map[reflect.Type][]string{
+			reflect.TypeOf(kubeonev1beta1.KubeOneCluster{}):              {"apiVersion", "kind", "name", "cloudProvider", "versions"},
+			reflect.TypeOf(kubeonev1beta1.BastionConfig{}):               {"address"},
+			reflect.TypeOf(kubeonev1beta1.CredentialsExecConfig{}):       {"command"},
+			reflect.TypeOf(kubeonev1beta1.VersionConfig{}):               {"kubernetes"},
+			reflect.TypeOf(kubeonev1beta1.DynamicWorkerConfig{}):         {"name", "replicas", "providerSpec"},
+			reflect.TypeOf(kubeonev1beta1.ProviderSpec{}):                {"cloudProviderSpec", "operatingSystem"},
+			reflect.TypeOf(kubeonev1beta1.ProviderStaticNetworkConfig{}): {"cidr", "gateway", "dns"},
+			reflect.TypeOf(kubeonev1beta1.DNSConfig{}):                   {"servers"},
+			reflect.TypeOf(kubeonev1beta1.PodNodeSelectorConfig{}):       {"configFilePath"},
+			reflect.TypeOf(kubeonev1beta1.StaticAuditLogConfig{}):        {"policyFilePath"},
+			reflect.TypeOf(kubeonev1beta1.OpenIDConnectConfig{}):         {"issuerUrl", "clientId"},
+		},
+		exclusive: map[reflect.Type]bool{
+			reflect.TypeOf(kubeonev1beta1.CNI{}):                    true,
+			reflect.TypeOf(kubeonev1beta1.ContainerRuntimeConfig{}): true,
+		},
+	},
+}
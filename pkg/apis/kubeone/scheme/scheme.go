@@ -34,6 +34,9 @@ var Scheme = runtime.NewScheme()
 // Codecs is a CodecFactory object used to provide encoding and decoding for the scheme
 var Codecs = serializer.NewCodecFactory(Scheme, serializer.EnableStrict)
 
+// LenientCodecs is a CodecFactory object which ignores unknown fields when decoding
+var LenientCodecs = serializer.NewCodecFactory(Scheme)
+
 func init() {
 	metav1.AddToGroupVersion(Scheme, schema.GroupVersion{Version: "v1"})
 	AddToScheme(Scheme)
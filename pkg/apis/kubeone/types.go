@@ -88,10 +88,51 @@ const (
 	OperatingSystemNameUnknown OperatingSystemName = ""
 )
 
+// SSHHostKeyCheck defines how SSH host keys are verified
+type SSHHostKeyCheck string
+
+const (
+	SSHHostKeyCheckIgnore          SSHHostKeyCheck = "Ignore"
+	SSHHostKeyCheckStrict          SSHHostKeyCheck = "Strict"
+	SSHHostKeyCheckTrustOnFirstUse SSHHostKeyCheck = "TrustOnFirstUse"
+)
+
+// SSHFileTransfer is the way of transferring files to and from the host
+type SSHFileTransfer string
+
+const (
+	SSHFileTransferAuto SSHFileTransfer = "Auto"
+	SSHFileTransferSFTP SSHFileTransfer = "SFTP"
+	SSHFileTransferExec SSHFileTransfer = "Exec"
+)
+
+// HostConnectionType is the way of connecting to the host
+type HostConnectionType string
+
+const (
+	HostConnectionSSH   HostConnectionType = "ssh"
+	HostConnectionLocal HostConnectionType = "local"
+)
+
+// PrivilegeEscalation is the way of running commands as root on the host
+type PrivilegeEscalation string
+
+const (
+	PrivilegeEscalationNone         PrivilegeEscalation = "none"
+	PrivilegeEscalationSudo         PrivilegeEscalation = "sudo"
+	PrivilegeEscalationSudoPassword PrivilegeEscalation = "sudo-password"
+	PrivilegeEscalationDoas         PrivilegeEscalation = "doas"
+)
+
 // HostConfig describes a single control plane node.
 type HostConfig struct {
 	// ID automatically assigned at runtime.
 	ID int `json:"-"`
+	// Connection is the way of connecting to the host. Possible values are "ssh" and "local".
+	// The "local" connection runs commands directly on the machine KubeOne is running on,
+	// without SSH, and all SSH settings of the host are ignored.
+	// Default value is "ssh".
+	Connection HostConnectionType `json:"connection,omitempty"`
 	// PublicAddress is externally accessible IP address from public internet.
 	PublicAddress string `json:"publicAddress"`
 	// PrivateAddress is internal RFC-1918 IP address.
@@ -102,9 +143,20 @@ type HostConfig struct {
 	// SSHUsername is system login name.
 	// Default value is "root".
 	SSHUsername string `json:"sshUsername,omitempty"`
-	// SSHPrivateKeyFile is path to the file with PRIVATE AND CLEANTEXT ssh key.
+	// SSHPrivateKeyFile is path to the file with PRIVATE ssh key.
+	// Encrypted keys are decrypted using the passphrase from .SSHPrivateKeyPassphraseFile,
+	// the KUBEONE_SSH_KEY_PASSPHRASE environment variable, or the interactive prompt.
 	// Default value is "".
 	SSHPrivateKeyFile string `json:"sshPrivateKeyFile,omitempty"`
+	// SSHPrivateKeyPassphraseFile is path to the file with the passphrase for the encrypted .SSHPrivateKeyFile.
+	// Default value is "".
+	SSHPrivateKeyPassphraseFile string `json:"sshPrivateKeyPassphraseFile,omitempty"`
+	// SSHCertificateFile is path to the file with the OpenSSH user certificate signed for the key
+	// from .SSHPrivateKeyFile, or for a key held by the SSH agent.
+	// If not set, the "<SSHPrivateKeyFile>-cert.pub" file is used if it exists, and certificates
+	// held by the SSH agent are used as well.
+	// Default value is "".
+	SSHCertificateFile string `json:"sshCertificateFile,omitempty"`
 	// SSHAgentSocket path (or reference to the environment) to the SSH agent unix domain socket.
 	// Default vaulue is "env:SSH_AUTH_SOCK".
 	SSHAgentSocket string `json:"sshAgentSocket,omitempty"`
@@ -117,6 +169,51 @@ type HostConfig struct {
 	// BastionUser is system login name to use when connecting to bastion host.
 	// Default value is "root".
 	BastionUser string `json:"bastionUser,omitempty"`
+	// BastionHostPublicKey pins the SSH host public key of the bastion host, in the
+	// authorized_keys format (e.g. "ssh-ed25519 AAAA...").
+	// Default value is "".
+	BastionHostPublicKey string `json:"bastionHostPublicKey,omitempty"`
+	// Bastions is a chain of bastion (or jump) hosts to connect through, in the given order,
+	// before reaching the host. It can't be used together with .Bastion.
+	// Default value is [].
+	Bastions []BastionConfig `json:"bastions,omitempty"`
+	// SSHHostPublicKey pins the SSH host public key of the host, in the
+	// authorized_keys format (e.g. "ssh-ed25519 AAAA...").
+	// If set, the host key presented by the host must match it regardless of .SSHHostKeyCheck.
+	// Default value is "".
+	SSHHostPublicKey string `json:"sshHostPublicKey,omitempty"`
+	// SSHHostKeyCheck controls how host keys of the host and the bastion host are verified.
+	// Possible values are "Ignore", "Strict" (keys must be present in .SSHKnownHostsFile) and
+	// "TrustOnFirstUse" (unknown keys are recorded in .SSHKnownHostsFile, mismatching keys are rejected).
+	// Default value is "Ignore".
+	SSHHostKeyCheck SSHHostKeyCheck `json:"sshHostKeyCheck,omitempty"`
+	// SSHKnownHostsFile is path to the known_hosts file used to verify host keys.
+	// Default value is "~/.ssh/known_hosts".
+	SSHKnownHostsFile string `json:"sshKnownHostsFile,omitempty"`
+	// SSHConfigFile is path to the OpenSSH client configuration file (e.g. "~/.ssh/config").
+	// If set, .PublicAddress is treated as the ssh_config host alias, and HostName, User, Port,
+	// IdentityFile, CertificateFile and ProxyJump from the file are used for settings not explicitly
+	// configured for the host (.SSHUsername, .SSHPort, .SSHPrivateKeyFile, .SSHCertificateFile,
+	// .Bastion and .Bastions).
+	// .PrivateAddress must be set explicitly to the IP address, as it otherwise defaults to the alias.
+	// Default value is "".
+	SSHConfigFile string `json:"sshConfigFile,omitempty"`
+	// SSHFileTransfer controls how files are transferred to and from the host.
+	// Possible values are "Auto" (SFTP is used, falling back to streaming files over
+	// commands run on the host if the SFTP subsystem is not available), "SFTP" and "Exec".
+	// Default value is "Auto".
+	SSHFileTransfer SSHFileTransfer `json:"sshFileTransfer,omitempty"`
+	// PrivilegeEscalation is the way of running commands as root on the host.
+	// Possible values are "none" (commands are run directly, e.g. when logging in as root),
+	// "sudo" (passwordless sudo), "sudo-password" (sudo with the password from .SudoPasswordFile
+	// or the KUBEONE_SUDO_PASSWORD environment variable, passed to the host over stdin) and
+	// "doas" (passwordless doas).
+	// Default value is "sudo".
+	PrivilegeEscalation PrivilegeEscalation `json:"privilegeEscalation,omitempty"`
+	// SudoPasswordFile is path to the file with the sudo password, used with the "sudo-password"
+	// privilege escalation.
+	// Default value is "".
+	SudoPasswordFile string `json:"sudoPasswordFile,omitempty"`
 	// Hostname is the hostname(1) of the host.
 	// Default value is populated at the runtime via running `hostname -f` command over ssh.
 	Hostname string `json:"hostname,omitempty"`
@@ -126,21 +223,69 @@ type HostConfig struct {
 	// Taints if not provided (i.e. nil) defaults to TaintEffectNoSchedule, with key node-role.kubernetes.io/master for
 	// control plane nodes.
 	// Explicitly empty (i.e. []corev1.Taint{}) means no taints will be applied (this is default for worker nodes).
+	// Taints are reconciled on every apply: taints removed from the manifest are removed from the node,
+	// other taints of the node are left untouched.
 	Taints []corev1.Taint `json:"taints,omitempty"`
+	// Labels are the labels of the node, merged over the labels of the control plane or static workers.
+	// Labels are reconciled on every apply: labels removed from the manifest are removed from the node,
+	// other labels of the node are left untouched.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the annotations of the node, merged over the annotations of the control plane or
+	// static workers, and reconciled on every apply like the labels.
+	Annotations map[string]string `json:"annotations,omitempty"`
 	// OperatingSystem information populated at the runtime.
 	OperatingSystem OperatingSystemName `json:"-"`
 }
 
+// BastionConfig describes a single bastion (or jump) host
+type BastionConfig struct {
+	// Address is an IP or hostname of the bastion host.
+	Address string `json:"address"`
+	// Port is SSH port to use when connecting to the bastion host.
+	// Default value is 22.
+	Port int `json:"port,omitempty"`
+	// User is system login name to use when connecting to the bastion host.
+	// Default value is the SSHUsername of the host.
+	User string `json:"user,omitempty"`
+	// SSHPrivateKeyFile is path to the file with PRIVATE ssh key used to authenticate to the bastion host.
+	// If neither SSHPrivateKeyFile nor SSHAgentSocket are set, credentials of the host are used.
+	// Default value is "".
+	SSHPrivateKeyFile string `json:"sshPrivateKeyFile,omitempty"`
+	// SSHPrivateKeyPassphraseFile is path to the file with the passphrase for the encrypted .SSHPrivateKeyFile.
+	// Default value is "".
+	SSHPrivateKeyPassphraseFile string `json:"sshPrivateKeyPassphraseFile,omitempty"`
+	// SSHCertificateFile is path to the file with the OpenSSH user certificate used to authenticate
+	// to the bastion host, signed for the key from .SSHPrivateKeyFile or for a key held by the SSH agent.
+	// Default value is "".
+	SSHCertificateFile string `json:"sshCertificateFile,omitempty"`
+	// SSHAgentSocket path (or reference to the environment) to the SSH agent unix domain socket
+	// used to authenticate to the bastion host.
+	// Default value is "".
+	SSHAgentSocket string `json:"sshAgentSocket,omitempty"`
+	// HostPublicKey pins the SSH host public key of the bastion host, in the
+	// authorized_keys format (e.g. "ssh-ed25519 AAAA...").
+	// Default value is "".
+	HostPublicKey string `json:"hostPublicKey,omitempty"`
+}
+
 // ControlPlaneConfig defines control plane nodes
 type ControlPlaneConfig struct {
 	// Hosts array of all control plane hosts.
 	Hosts []HostConfig `json:"hosts"`
+	// Labels are the labels of all control plane nodes. The labels of the hosts take precedence.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the annotations of all control plane nodes. The annotations of the hosts take precedence.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // StaticWorkersConfig defines static worker nodes provisioned by KubeOne and kubeadm
 type StaticWorkersConfig struct {
 	// Hosts
 	Hosts []HostConfig `json:"hosts,omitempty"`
+	// Labels are the labels of all static worker nodes. The labels of the hosts take precedence.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are the annotations of all static worker nodes. The annotations of the hosts take precedence.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // APIEndpoint is the endpoint used to communicate with the Kubernetes API
@@ -181,6 +326,10 @@ type CloudProviderSpec struct {
 	CSIMigrationComplete bool `json:"csiMigrationComplete,omitempty"`
 	// CloudConfig
 	CloudConfig string `json:"cloudConfig,omitempty"`
+	// CredentialsExec configures the plugin printing the cloud provider
+	// credentials. The environment variables take precedence over the
+	// plugin, and the plugin takes precedence over the credentials file.
+	CredentialsExec *CredentialsExecConfig `json:"credentialsExec,omitempty"`
 	// AWS
 	AWS *AWSSpec `json:"aws,omitempty"`
 	// Azure
@@ -201,6 +350,16 @@ type CloudProviderSpec struct {
 	None *NoneSpec `json:"none,omitempty"`
 }
 
+// CredentialsExecConfig configures the credentials plugin, a command printing
+// the cloud provider credentials as a JSON object, keyed by the names of the
+// environment variables, e.g. HCLOUD_TOKEN
+type CredentialsExecConfig struct {
+	// Command is the path of the plugin, or the name of the plugin in the PATH
+	Command string `json:"command"`
+	// Args are the arguments of the plugin
+	Args []string `json:"args,omitempty"`
+}
+
 // AWSSpec defines the AWS cloud provider
 type AWSSpec struct{}
 
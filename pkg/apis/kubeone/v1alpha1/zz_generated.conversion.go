@@ -349,6 +349,7 @@ func autoConvert_kubeone_CloudProviderSpec_To_v1alpha1_CloudProviderSpec(in *kub
 	// WARNING: in.CSIMigration requires manual conversion: does not exist in peer-type
 	// WARNING: in.CSIMigrationComplete requires manual conversion: does not exist in peer-type
 	out.CloudConfig = in.CloudConfig
+	// WARNING: in.CredentialsExec requires manual conversion: does not exist in peer-type
 	// WARNING: in.AWS requires manual conversion: does not exist in peer-type
 	// WARNING: in.Azure requires manual conversion: does not exist in peer-type
 	// WARNING: in.DigitalOcean requires manual conversion: does not exist in peer-type
@@ -493,18 +494,32 @@ func autoConvert_v1alpha1_HostConfig_To_kubeone_HostConfig(in *HostConfig, out *
 
 func autoConvert_kubeone_HostConfig_To_v1alpha1_HostConfig(in *kubeone.HostConfig, out *HostConfig, s conversion.Scope) error {
 	out.ID = in.ID
+	// WARNING: in.Connection requires manual conversion: does not exist in peer-type
 	out.PublicAddress = in.PublicAddress
 	out.PrivateAddress = in.PrivateAddress
 	out.SSHPort = in.SSHPort
 	out.SSHUsername = in.SSHUsername
 	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
+	// WARNING: in.SSHPrivateKeyPassphraseFile requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHCertificateFile requires manual conversion: does not exist in peer-type
 	out.SSHAgentSocket = in.SSHAgentSocket
 	out.Bastion = in.Bastion
 	out.BastionPort = in.BastionPort
 	out.BastionUser = in.BastionUser
+	// WARNING: in.BastionHostPublicKey requires manual conversion: does not exist in peer-type
+	// WARNING: in.Bastions requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHHostPublicKey requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHHostKeyCheck requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHKnownHostsFile requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHConfigFile requires manual conversion: does not exist in peer-type
+	// WARNING: in.SSHFileTransfer requires manual conversion: does not exist in peer-type
+	// WARNING: in.PrivilegeEscalation requires manual conversion: does not exist in peer-type
+	// WARNING: in.SudoPasswordFile requires manual conversion: does not exist in peer-type
 	out.Hostname = in.Hostname
 	out.IsLeader = in.IsLeader
 	// WARNING: in.Taints requires manual conversion: does not exist in peer-type
+	// WARNING: in.Labels requires manual conversion: does not exist in peer-type
+	// WARNING: in.Annotations requires manual conversion: does not exist in peer-type
 	out.OperatingSystem = string(in.OperatingSystem)
 	return nil
 }
@@ -75,6 +75,8 @@ func SetDefaults_Hosts(obj *KubeOneCluster) {
 		}
 		obj.ControlPlane.Hosts[idx].ID = idx
 		defaultHostConfig(&obj.ControlPlane.Hosts[idx])
+		obj.ControlPlane.Hosts[idx].Labels = defaultsMap(obj.ControlPlane.Hosts[idx].Labels, obj.ControlPlane.Labels)
+		obj.ControlPlane.Hosts[idx].Annotations = defaultsMap(obj.ControlPlane.Hosts[idx].Annotations, obj.ControlPlane.Annotations)
 		if obj.ControlPlane.Hosts[idx].Taints == nil {
 			obj.ControlPlane.Hosts[idx].Taints = []corev1.Taint{
 				{
@@ -94,6 +96,8 @@ func SetDefaults_Hosts(obj *KubeOneCluster) {
 		// continue assinging IDs after control plane hosts. This way every node gets a unique ID regardless of the different host slices
 		obj.StaticWorkers.Hosts[idx].ID = idx + len(obj.ControlPlane.Hosts)
 		defaultHostConfig(&obj.StaticWorkers.Hosts[idx])
+		obj.StaticWorkers.Hosts[idx].Labels = defaultsMap(obj.StaticWorkers.Hosts[idx].Labels, obj.StaticWorkers.Labels)
+		obj.StaticWorkers.Hosts[idx].Annotations = defaultsMap(obj.StaticWorkers.Hosts[idx].Annotations, obj.StaticWorkers.Annotations)
 		if obj.StaticWorkers.Hosts[idx].Taints == nil {
 			obj.StaticWorkers.Hosts[idx].Taints = []corev1.Taint{}
 		}
@@ -264,13 +268,34 @@ func defaultHostConfig(obj *HostConfig) {
 	if len(obj.PrivateAddress) == 0 && len(obj.PublicAddress) > 0 {
 		obj.PrivateAddress = obj.PublicAddress
 	}
-	if obj.SSHPrivateKeyFile == "" {
-		obj.SSHAgentSocket = defaults(obj.SSHAgentSocket, "env:SSH_AUTH_SOCK")
+	// settings from the ssh_config file take precedence over the defaults,
+	// so they are resolved when connecting to the host
+	if obj.SSHConfigFile == "" {
+		if obj.SSHPrivateKeyFile == "" {
+			obj.SSHAgentSocket = defaults(obj.SSHAgentSocket, "env:SSH_AUTH_SOCK")
+		}
+		obj.SSHUsername = defaults(obj.SSHUsername, "root")
+		obj.SSHPort = defaulti(obj.SSHPort, 22)
 	}
-	obj.SSHUsername = defaults(obj.SSHUsername, "root")
-	obj.SSHPort = defaulti(obj.SSHPort, 22)
 	obj.BastionPort = defaulti(obj.BastionPort, 22)
 	obj.BastionUser = defaults(obj.BastionUser, obj.SSHUsername)
+	for idx := range obj.Bastions {
+		obj.Bastions[idx].Port = defaulti(obj.Bastions[idx].Port, 22)
+		obj.Bastions[idx].User = defaults(obj.Bastions[idx].User, obj.SSHUsername)
+	}
+	obj.SSHKnownHostsFile = defaults(obj.SSHKnownHostsFile, "~/.ssh/known_hosts")
+	if obj.Connection == "" {
+		obj.Connection = HostConnectionSSH
+	}
+	if obj.SSHHostKeyCheck == "" {
+		obj.SSHHostKeyCheck = SSHHostKeyCheckIgnore
+	}
+	if obj.SSHFileTransfer == "" {
+		obj.SSHFileTransfer = SSHFileTransferAuto
+	}
+	if obj.PrivilegeEscalation == "" {
+		obj.PrivilegeEscalation = PrivilegeEscalationSudo
+	}
 }
 
 func defaults(input, defaultValue string) string {
@@ -286,3 +311,21 @@ func defaulti(input, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// defaultsMap returns the input merged over the default values, without
+// modifying either of them
+func defaultsMap(input, defaultValues map[string]string) map[string]string {
+	if len(defaultValues) == 0 {
+		return input
+	}
+
+	merged := make(map[string]string, len(input)+len(defaultValues))
+	for k, v := range defaultValues {
+		merged[k] = v
+	}
+	for k, v := range input {
+		merged[k] = v
+	}
+
+	return merged
+}
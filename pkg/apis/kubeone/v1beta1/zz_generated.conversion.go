@@ -87,6 +87,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*BastionConfig)(nil), (*kubeone.BastionConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_BastionConfig_To_kubeone_BastionConfig(a.(*BastionConfig), b.(*kubeone.BastionConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kubeone.BastionConfig)(nil), (*BastionConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kubeone_BastionConfig_To_v1beta1_BastionConfig(a.(*kubeone.BastionConfig), b.(*BastionConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*BinaryAsset)(nil), (*kubeone.BinaryAsset)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_BinaryAsset_To_kubeone_BinaryAsset(a.(*BinaryAsset), b.(*kubeone.BinaryAsset), scope)
 	}); err != nil {
@@ -177,6 +187,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CredentialsExecConfig)(nil), (*kubeone.CredentialsExecConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CredentialsExecConfig_To_kubeone_CredentialsExecConfig(a.(*CredentialsExecConfig), b.(*kubeone.CredentialsExecConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*kubeone.CredentialsExecConfig)(nil), (*CredentialsExecConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_kubeone_CredentialsExecConfig_To_v1beta1_CredentialsExecConfig(a.(*kubeone.CredentialsExecConfig), b.(*CredentialsExecConfig), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*DNSConfig)(nil), (*kubeone.DNSConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_DNSConfig_To_kubeone_DNSConfig(a.(*DNSConfig), b.(*kubeone.DNSConfig), scope)
 	}); err != nil {
@@ -656,6 +676,40 @@ func Convert_kubeone_AzureSpec_To_v1beta1_AzureSpec(in *kubeone.AzureSpec, out *
 	return autoConvert_kubeone_AzureSpec_To_v1beta1_AzureSpec(in, out, s)
 }
 
+func autoConvert_v1beta1_BastionConfig_To_kubeone_BastionConfig(in *BastionConfig, out *kubeone.BastionConfig, s conversion.Scope) error {
+	out.Address = in.Address
+	out.Port = in.Port
+	out.User = in.User
+	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
+	out.SSHPrivateKeyPassphraseFile = in.SSHPrivateKeyPassphraseFile
+	out.SSHCertificateFile = in.SSHCertificateFile
+	out.SSHAgentSocket = in.SSHAgentSocket
+	out.HostPublicKey = in.HostPublicKey
+	return nil
+}
+
+// Convert_v1beta1_BastionConfig_To_kubeone_BastionConfig is an autogenerated conversion function.
+func Convert_v1beta1_BastionConfig_To_kubeone_BastionConfig(in *BastionConfig, out *kubeone.BastionConfig, s conversion.Scope) error {
+	return autoConvert_v1beta1_BastionConfig_To_kubeone_BastionConfig(in, out, s)
+}
+
+func autoConvert_kubeone_BastionConfig_To_v1beta1_BastionConfig(in *kubeone.BastionConfig, out *BastionConfig, s conversion.Scope) error {
+	out.Address = in.Address
+	out.Port = in.Port
+	out.User = in.User
+	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
+	out.SSHPrivateKeyPassphraseFile = in.SSHPrivateKeyPassphraseFile
+	out.SSHCertificateFile = in.SSHCertificateFile
+	out.SSHAgentSocket = in.SSHAgentSocket
+	out.HostPublicKey = in.HostPublicKey
+	return nil
+}
+
+// Convert_kubeone_BastionConfig_To_v1beta1_BastionConfig is an autogenerated conversion function.
+func Convert_kubeone_BastionConfig_To_v1beta1_BastionConfig(in *kubeone.BastionConfig, out *BastionConfig, s conversion.Scope) error {
+	return autoConvert_kubeone_BastionConfig_To_v1beta1_BastionConfig(in, out, s)
+}
+
 func autoConvert_v1beta1_BinaryAsset_To_kubeone_BinaryAsset(in *BinaryAsset, out *kubeone.BinaryAsset, s conversion.Scope) error {
 	out.URL = in.URL
 	return nil
@@ -725,6 +779,7 @@ func autoConvert_v1beta1_CloudProviderSpec_To_kubeone_CloudProviderSpec(in *Clou
 	out.CSIMigration = in.CSIMigration
 	out.CSIMigrationComplete = in.CSIMigrationComplete
 	out.CloudConfig = in.CloudConfig
+	out.CredentialsExec = (*kubeone.CredentialsExecConfig)(unsafe.Pointer(in.CredentialsExec))
 	out.AWS = (*kubeone.AWSSpec)(unsafe.Pointer(in.AWS))
 	out.Azure = (*kubeone.AzureSpec)(unsafe.Pointer(in.Azure))
 	out.DigitalOcean = (*kubeone.DigitalOceanSpec)(unsafe.Pointer(in.DigitalOcean))
@@ -747,6 +802,7 @@ func autoConvert_kubeone_CloudProviderSpec_To_v1beta1_CloudProviderSpec(in *kube
 	out.CSIMigration = in.CSIMigration
 	out.CSIMigrationComplete = in.CSIMigrationComplete
 	out.CloudConfig = in.CloudConfig
+	out.CredentialsExec = (*CredentialsExecConfig)(unsafe.Pointer(in.CredentialsExec))
 	out.AWS = (*AWSSpec)(unsafe.Pointer(in.AWS))
 	out.Azure = (*AzureSpec)(unsafe.Pointer(in.Azure))
 	out.DigitalOcean = (*DigitalOceanSpec)(unsafe.Pointer(in.DigitalOcean))
@@ -852,6 +908,8 @@ func Convert_kubeone_ContainerRuntimeDocker_To_v1beta1_ContainerRuntimeDocker(in
 
 func autoConvert_v1beta1_ControlPlaneConfig_To_kubeone_ControlPlaneConfig(in *ControlPlaneConfig, out *kubeone.ControlPlaneConfig, s conversion.Scope) error {
 	out.Hosts = *(*[]kubeone.HostConfig)(unsafe.Pointer(&in.Hosts))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
 	return nil
 }
 
@@ -862,6 +920,8 @@ func Convert_v1beta1_ControlPlaneConfig_To_kubeone_ControlPlaneConfig(in *Contro
 
 func autoConvert_kubeone_ControlPlaneConfig_To_v1beta1_ControlPlaneConfig(in *kubeone.ControlPlaneConfig, out *ControlPlaneConfig, s conversion.Scope) error {
 	out.Hosts = *(*[]HostConfig)(unsafe.Pointer(&in.Hosts))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
 	return nil
 }
 
@@ -870,6 +930,28 @@ func Convert_kubeone_ControlPlaneConfig_To_v1beta1_ControlPlaneConfig(in *kubeon
 	return autoConvert_kubeone_ControlPlaneConfig_To_v1beta1_ControlPlaneConfig(in, out, s)
 }
 
+func autoConvert_v1beta1_CredentialsExecConfig_To_kubeone_CredentialsExecConfig(in *CredentialsExecConfig, out *kubeone.CredentialsExecConfig, s conversion.Scope) error {
+	out.Command = in.Command
+	out.Args = *(*[]string)(unsafe.Pointer(&in.Args))
+	return nil
+}
+
+// Convert_v1beta1_CredentialsExecConfig_To_kubeone_CredentialsExecConfig is an autogenerated conversion function.
+func Convert_v1beta1_CredentialsExecConfig_To_kubeone_CredentialsExecConfig(in *CredentialsExecConfig, out *kubeone.CredentialsExecConfig, s conversion.Scope) error {
+	return autoConvert_v1beta1_CredentialsExecConfig_To_kubeone_CredentialsExecConfig(in, out, s)
+}
+
+func autoConvert_kubeone_CredentialsExecConfig_To_v1beta1_CredentialsExecConfig(in *kubeone.CredentialsExecConfig, out *CredentialsExecConfig, s conversion.Scope) error {
+	out.Command = in.Command
+	out.Args = *(*[]string)(unsafe.Pointer(&in.Args))
+	return nil
+}
+
+// Convert_kubeone_CredentialsExecConfig_To_v1beta1_CredentialsExecConfig is an autogenerated conversion function.
+func Convert_kubeone_CredentialsExecConfig_To_v1beta1_CredentialsExecConfig(in *kubeone.CredentialsExecConfig, out *CredentialsExecConfig, s conversion.Scope) error {
+	return autoConvert_kubeone_CredentialsExecConfig_To_v1beta1_CredentialsExecConfig(in, out, s)
+}
+
 func autoConvert_v1beta1_DNSConfig_To_kubeone_DNSConfig(in *DNSConfig, out *kubeone.DNSConfig, s conversion.Scope) error {
 	out.Servers = *(*[]string)(unsafe.Pointer(&in.Servers))
 	return nil
@@ -1046,18 +1128,32 @@ func Convert_kubeone_HetznerSpec_To_v1beta1_HetznerSpec(in *kubeone.HetznerSpec,
 
 func autoConvert_v1beta1_HostConfig_To_kubeone_HostConfig(in *HostConfig, out *kubeone.HostConfig, s conversion.Scope) error {
 	out.ID = in.ID
+	out.Connection = kubeone.HostConnectionType(in.Connection)
 	out.PublicAddress = in.PublicAddress
 	out.PrivateAddress = in.PrivateAddress
 	out.SSHPort = in.SSHPort
 	out.SSHUsername = in.SSHUsername
 	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
+	out.SSHPrivateKeyPassphraseFile = in.SSHPrivateKeyPassphraseFile
+	out.SSHCertificateFile = in.SSHCertificateFile
 	out.SSHAgentSocket = in.SSHAgentSocket
 	out.Bastion = in.Bastion
 	out.BastionPort = in.BastionPort
 	out.BastionUser = in.BastionUser
+	out.BastionHostPublicKey = in.BastionHostPublicKey
+	out.Bastions = *(*[]kubeone.BastionConfig)(unsafe.Pointer(&in.Bastions))
+	out.SSHHostPublicKey = in.SSHHostPublicKey
+	out.SSHHostKeyCheck = kubeone.SSHHostKeyCheck(in.SSHHostKeyCheck)
+	out.SSHKnownHostsFile = in.SSHKnownHostsFile
+	out.SSHConfigFile = in.SSHConfigFile
+	out.SSHFileTransfer = kubeone.SSHFileTransfer(in.SSHFileTransfer)
+	out.PrivilegeEscalation = kubeone.PrivilegeEscalation(in.PrivilegeEscalation)
+	out.SudoPasswordFile = in.SudoPasswordFile
 	out.Hostname = in.Hostname
 	out.IsLeader = in.IsLeader
 	out.Taints = *(*[]v1.Taint)(unsafe.Pointer(&in.Taints))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
 	out.OperatingSystem = kubeone.OperatingSystemName(in.OperatingSystem)
 	return nil
 }
@@ -1069,18 +1165,32 @@ func Convert_v1beta1_HostConfig_To_kubeone_HostConfig(in *HostConfig, out *kubeo
 
 func autoConvert_kubeone_HostConfig_To_v1beta1_HostConfig(in *kubeone.HostConfig, out *HostConfig, s conversion.Scope) error {
 	out.ID = in.ID
+	out.Connection = HostConnectionType(in.Connection)
 	out.PublicAddress = in.PublicAddress
 	out.PrivateAddress = in.PrivateAddress
 	out.SSHPort = in.SSHPort
 	out.SSHUsername = in.SSHUsername
 	out.SSHPrivateKeyFile = in.SSHPrivateKeyFile
+	out.SSHPrivateKeyPassphraseFile = in.SSHPrivateKeyPassphraseFile
+	out.SSHCertificateFile = in.SSHCertificateFile
 	out.SSHAgentSocket = in.SSHAgentSocket
 	out.Bastion = in.Bastion
 	out.BastionPort = in.BastionPort
 	out.BastionUser = in.BastionUser
+	out.BastionHostPublicKey = in.BastionHostPublicKey
+	out.Bastions = *(*[]BastionConfig)(unsafe.Pointer(&in.Bastions))
+	out.SSHHostPublicKey = in.SSHHostPublicKey
+	out.SSHHostKeyCheck = SSHHostKeyCheck(in.SSHHostKeyCheck)
+	out.SSHKnownHostsFile = in.SSHKnownHostsFile
+	out.SSHConfigFile = in.SSHConfigFile
+	out.SSHFileTransfer = SSHFileTransfer(in.SSHFileTransfer)
+	out.PrivilegeEscalation = PrivilegeEscalation(in.PrivilegeEscalation)
+	out.SudoPasswordFile = in.SudoPasswordFile
 	out.Hostname = in.Hostname
 	out.IsLeader = in.IsLeader
 	out.Taints = *(*[]v1.Taint)(unsafe.Pointer(&in.Taints))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
 	out.OperatingSystem = OperatingSystemName(in.OperatingSystem)
 	return nil
 }
@@ -1610,6 +1720,8 @@ func Convert_kubeone_StaticAuditLogConfig_To_v1beta1_StaticAuditLogConfig(in *ku
 
 func autoConvert_v1beta1_StaticWorkersConfig_To_kubeone_StaticWorkersConfig(in *StaticWorkersConfig, out *kubeone.StaticWorkersConfig, s conversion.Scope) error {
 	out.Hosts = *(*[]kubeone.HostConfig)(unsafe.Pointer(&in.Hosts))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
 	return nil
 }
 
@@ -1620,6 +1732,8 @@ func Convert_v1beta1_StaticWorkersConfig_To_kubeone_StaticWorkersConfig(in *Stat
 
 func autoConvert_kubeone_StaticWorkersConfig_To_v1beta1_StaticWorkersConfig(in *kubeone.StaticWorkersConfig, out *StaticWorkersConfig, s conversion.Scope) error {
 	out.Hosts = *(*[]HostConfig)(unsafe.Pointer(&in.Hosts))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
+	out.Annotations = *(*map[string]string)(unsafe.Pointer(&in.Annotations))
 	return nil
 }
 
@@ -22,9 +22,12 @@ import (
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
+	"golang.org/x/crypto/ssh"
 
 	"k8c.io/kubeone/pkg/apis/kubeone"
 
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -37,7 +40,7 @@ func ValidateKubeOneCluster(c kubeone.KubeOneCluster) field.ErrorList {
 	}
 	allErrs = append(allErrs, ValidateControlPlaneConfig(c.ControlPlane, field.NewPath("controlPlane"))...)
 	allErrs = append(allErrs, ValidateAPIEndpoint(c.APIEndpoint, field.NewPath("apiEndpoint"))...)
-	allErrs = append(allErrs, ValidateCloudProviderSpec(c.CloudProvider, field.NewPath("provider"))...)
+	allErrs = append(allErrs, ValidateCloudProviderSpec(c.CloudProvider, field.NewPath("cloudProvider"))...)
 	allErrs = append(allErrs, ValidateVersionConfig(c.Versions, field.NewPath("versions"))...)
 	allErrs = append(allErrs, ValidateContainerRuntimeConfig(c.ContainerRuntime, c.Versions, field.NewPath("containerRuntime"))...)
 	allErrs = append(allErrs, ValidateClusterNetworkConfig(c.ClusterNetwork, field.NewPath("clusterNetwork"))...)
@@ -162,6 +165,10 @@ func ValidateCloudProviderSpec(p kubeone.CloudProviderSpec, fldPath *field.Path)
 		allErrs = append(allErrs, field.Forbidden(fldPath.Child("csiMigrationComplete"), "csiMigrationComplete requires csiMigration to be enabled"))
 	}
 
+	if p.CredentialsExec != nil && len(p.CredentialsExec.Command) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("credentialsExec", "command"), ".cloudProvider.credentialsExec.command is required"))
+	}
+
 	return allErrs
 }
 
@@ -384,25 +391,99 @@ func ValidateHostConfig(hosts []kubeone.HostConfig, fldPath *field.Path) field.E
 	allErrs := field.ErrorList{}
 
 	leaderFound := false
-	for _, h := range hosts {
+	for i, h := range hosts {
+		hostPath := fldPath.Index(i)
+
 		if leaderFound && h.IsLeader {
-			allErrs = append(allErrs, field.Invalid(fldPath, h.IsLeader, "only one leader is allowed"))
+			allErrs = append(allErrs, field.Invalid(hostPath.Child("isLeader"), h.IsLeader, "only one leader is allowed"))
 		}
 		if h.IsLeader {
 			leaderFound = true
 		}
 		if len(h.PublicAddress) == 0 {
-			allErrs = append(allErrs, field.Required(fldPath, "no public IP/address given"))
+			allErrs = append(allErrs, field.Required(hostPath.Child("publicAddress"), "no public IP/address given"))
 		}
 		if len(h.PrivateAddress) == 0 {
-			allErrs = append(allErrs, field.Required(fldPath, "no private IP/address givevn"))
+			allErrs = append(allErrs, field.Required(hostPath.Child("privateAddress"), "no private IP/address given"))
+		} else if len(h.SSHConfigFile) > 0 && net.ParseIP(h.PrivateAddress) == nil {
+			// the private address defaults to the public address, which is
+			// the ssh_config host alias
+			allErrs = append(allErrs, field.Invalid(hostPath.Child("privateAddress"), h.PrivateAddress, "private IP address must be given when sshConfigFile is used"))
+		}
+		switch h.Connection {
+		case "", kubeone.HostConnectionSSH, kubeone.HostConnectionLocal:
+		default:
+			allErrs = append(allErrs, field.NotSupported(hostPath.Child("connection"), h.Connection, []string{
+				string(kubeone.HostConnectionSSH),
+				string(kubeone.HostConnectionLocal),
+			}))
+		}
+		// credentials and username can be resolved from the ssh_config file,
+		// and are not needed for the local connection
+		if len(h.SSHConfigFile) == 0 && h.Connection != kubeone.HostConnectionLocal {
+			if len(h.SSHPrivateKeyFile) == 0 && len(h.SSHAgentSocket) == 0 {
+				allErrs = append(allErrs, field.Invalid(hostPath.Child("sshPrivateKeyFile"), h.SSHPrivateKeyFile, "neither SSH private key nor agent socket given, don't know how to authenticate"))
+				allErrs = append(allErrs, field.Invalid(hostPath.Child("sshAgentSocket"), h.SSHAgentSocket, "neither SSH private key nor agent socket given, don't know how to authenticate"))
+			}
+			if len(h.SSHUsername) == 0 {
+				allErrs = append(allErrs, field.Required(hostPath.Child("sshUsername"), "no SSH username given"))
+			}
+		}
+		switch h.SSHHostKeyCheck {
+		case "", kubeone.SSHHostKeyCheckIgnore, kubeone.SSHHostKeyCheckStrict, kubeone.SSHHostKeyCheckTrustOnFirstUse:
+		default:
+			allErrs = append(allErrs, field.NotSupported(hostPath.Child("sshHostKeyCheck"), h.SSHHostKeyCheck, []string{
+				string(kubeone.SSHHostKeyCheckIgnore),
+				string(kubeone.SSHHostKeyCheckStrict),
+				string(kubeone.SSHHostKeyCheckTrustOnFirstUse),
+			}))
+		}
+		switch h.SSHFileTransfer {
+		case "", kubeone.SSHFileTransferAuto, kubeone.SSHFileTransferSFTP, kubeone.SSHFileTransferExec:
+		default:
+			allErrs = append(allErrs, field.NotSupported(hostPath.Child("sshFileTransfer"), h.SSHFileTransfer, []string{
+				string(kubeone.SSHFileTransferAuto),
+				string(kubeone.SSHFileTransferSFTP),
+				string(kubeone.SSHFileTransferExec),
+			}))
 		}
-		if len(h.SSHPrivateKeyFile) == 0 && len(h.SSHAgentSocket) == 0 {
-			allErrs = append(allErrs, field.Invalid(fldPath, h.SSHPrivateKeyFile, "neither SSH private key nor agent socket given, don't know how to authenticate"))
-			allErrs = append(allErrs, field.Invalid(fldPath, h.SSHAgentSocket, "neither SSH private key nor agent socket given, don't know how to authenticate"))
+		switch h.PrivilegeEscalation {
+		case "", kubeone.PrivilegeEscalationNone, kubeone.PrivilegeEscalationSudo, kubeone.PrivilegeEscalationSudoPassword, kubeone.PrivilegeEscalationDoas:
+		default:
+			allErrs = append(allErrs, field.NotSupported(hostPath.Child("privilegeEscalation"), h.PrivilegeEscalation, []string{
+				string(kubeone.PrivilegeEscalationNone),
+				string(kubeone.PrivilegeEscalationSudo),
+				string(kubeone.PrivilegeEscalationSudoPassword),
+				string(kubeone.PrivilegeEscalationDoas),
+			}))
 		}
-		if len(h.SSHUsername) == 0 {
-			allErrs = append(allErrs, field.Required(fldPath, "no SSH username given"))
+		if len(h.SudoPasswordFile) > 0 && h.PrivilegeEscalation != kubeone.PrivilegeEscalationSudoPassword {
+			allErrs = append(allErrs, field.Forbidden(hostPath.Child("sudoPasswordFile"), "sudoPasswordFile can be used only with the sudo-password privilege escalation"))
+		}
+		if len(h.SSHHostPublicKey) > 0 {
+			if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(h.SSHHostPublicKey)); err != nil {
+				allErrs = append(allErrs, field.Invalid(hostPath.Child("sshHostPublicKey"), h.SSHHostPublicKey, "unable to parse SSH host public key"))
+			}
+		}
+		if len(h.BastionHostPublicKey) > 0 {
+			if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(h.BastionHostPublicKey)); err != nil {
+				allErrs = append(allErrs, field.Invalid(hostPath.Child("bastionHostPublicKey"), h.BastionHostPublicKey, "unable to parse SSH host public key"))
+			}
+		}
+		if len(h.Bastions) > 0 && len(h.Bastion) > 0 {
+			allErrs = append(allErrs, field.Forbidden(hostPath.Child("bastions"), "bastions and bastion can't be used at the same time"))
+		}
+		allErrs = append(allErrs, metav1validation.ValidateLabels(h.Labels, hostPath.Child("labels"))...)
+		allErrs = append(allErrs, apivalidation.ValidateAnnotations(h.Annotations, hostPath.Child("annotations"))...)
+		for j, b := range h.Bastions {
+			if len(b.Address) == 0 {
+				allErrs = append(allErrs, field.Required(hostPath.Child("bastions").Index(j).Child("address"), "no bastion address given"))
+			}
+			if len(b.HostPublicKey) > 0 {
+				if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(b.HostPublicKey)); err != nil {
+					allErrs = append(allErrs, field.Invalid(hostPath.Child("bastions").Index(j).Child("hostPublicKey"), b.HostPublicKey, "unable to parse SSH host public key"))
+				}
+			}
 		}
 	}
 
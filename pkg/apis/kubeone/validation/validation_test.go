@@ -540,6 +540,22 @@ func TestValidateCloudProviderSpec(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "credentials plugin",
+			providerConfig: kubeone.CloudProviderSpec{
+				Hetzner:         &kubeone.HetznerSpec{},
+				CredentialsExec: &kubeone.CredentialsExecConfig{Command: "vault-creds", Args: []string{"hetzner"}},
+			},
+			expectedError: false,
+		},
+		{
+			name: "credentials plugin without command",
+			providerConfig: kubeone.CloudProviderSpec{
+				Hetzner:         &kubeone.HetznerSpec{},
+				CredentialsExec: &kubeone.CredentialsExecConfig{Args: []string{"hetzner"}},
+			},
+			expectedError: true,
+		},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -1432,6 +1448,52 @@ func TestValidateHostConfig(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "credentials and username resolved from ssh config",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:  "cp-1",
+					PrivateAddress: "192.168.0.1",
+					SSHConfigFile:  "~/.ssh/config",
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "ssh config without private IP address",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:  "cp-1",
+					PrivateAddress: "cp-1",
+					SSHConfigFile:  "~/.ssh/config",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "local connection without credentials",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:  "192.168.1.1",
+					PrivateAddress: "192.168.0.1",
+					Connection:     kubeone.HostConnectionLocal,
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "unknown connection type",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Connection:        "telnet",
+				},
+			},
+			expectedError: true,
+		},
 		{
 			name: "one valid host config and one invalid host config (no username)",
 			hostConfig: []kubeone.HostConfig{
@@ -1474,6 +1536,202 @@ func TestValidateHostConfig(t *testing.T) {
 			},
 			expectedError: true,
 		},
+		{
+			name: "valid host key check and pinned host keys",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:        "192.168.1.1",
+					PrivateAddress:       "192.168.0.1",
+					SSHPrivateKeyFile:    "test",
+					SSHUsername:          "root",
+					SSHHostKeyCheck:      kubeone.SSHHostKeyCheckTrustOnFirstUse,
+					SSHHostPublicKey:     "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+					BastionHostPublicKey: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "valid bastion chain",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Bastions: []kubeone.BastionConfig{
+						{Address: "jump.example.com", User: "jump"},
+						{Address: "10.0.0.1", Port: 2222},
+					},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "bastion chain without address",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Bastions: []kubeone.BastionConfig{
+						{Address: "jump.example.com"},
+						{Port: 2222},
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "bastion and bastion chain at the same time",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Bastion:           "10.0.0.1",
+					Bastions: []kubeone.BastionConfig{
+						{Address: "jump.example.com"},
+					},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid host key check",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					SSHHostKeyCheck:   "accept-new",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "exec file transfer",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					SSHFileTransfer:   kubeone.SSHFileTransferExec,
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid file transfer",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					SSHFileTransfer:   "scp",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "sudo with password",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:       "192.168.1.1",
+					PrivateAddress:      "192.168.0.1",
+					SSHPrivateKeyFile:   "test",
+					SSHUsername:         "admin",
+					PrivilegeEscalation: kubeone.PrivilegeEscalationSudoPassword,
+					SudoPasswordFile:    "/home/me/.sudo-password",
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid privilege escalation",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:       "192.168.1.1",
+					PrivateAddress:      "192.168.0.1",
+					SSHPrivateKeyFile:   "test",
+					SSHUsername:         "admin",
+					PrivilegeEscalation: "su",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "sudo password file without sudo-password privilege escalation",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:       "192.168.1.1",
+					PrivateAddress:      "192.168.0.1",
+					SSHPrivateKeyFile:   "test",
+					SSHUsername:         "admin",
+					PrivilegeEscalation: kubeone.PrivilegeEscalationSudo,
+					SudoPasswordFile:    "/home/me/.sudo-password",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid pinned host key",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					SSHHostPublicKey:  "not-a-key",
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "valid labels and annotations",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Labels:            map[string]string{"example.com/zone": "a"},
+					Annotations:       map[string]string{"example.com/owner": "team a"},
+				},
+			},
+			expectedError: false,
+		},
+		{
+			name: "invalid label value",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Labels:            map[string]string{"example.com/zone": "zone a"},
+				},
+			},
+			expectedError: true,
+		},
+		{
+			name: "invalid annotation key",
+			hostConfig: []kubeone.HostConfig{
+				{
+					PublicAddress:     "192.168.1.1",
+					PrivateAddress:    "192.168.0.1",
+					SSHPrivateKeyFile: "test",
+					SSHUsername:       "root",
+					Annotations:       map[string]string{"example.com/owner/team": "a"},
+				},
+			},
+			expectedError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -1487,6 +1745,38 @@ func TestValidateHostConfig(t *testing.T) {
 	}
 }
 
+func TestValidateHostConfigFieldPaths(t *testing.T) {
+	hosts := []kubeone.HostConfig{
+		{
+			PublicAddress:     "192.168.1.1",
+			PrivateAddress:    "192.168.0.1",
+			SSHPrivateKeyFile: "test",
+			SSHUsername:       "root",
+		},
+		{
+			PublicAddress:       "192.168.1.2",
+			SSHPrivateKeyFile:   "test",
+			SSHUsername:         "root",
+			PrivilegeEscalation: "su",
+		},
+	}
+
+	expected := []string{
+		"controlPlane.hosts[1].privateAddress",
+		"controlPlane.hosts[1].privilegeEscalation",
+	}
+
+	errs := ValidateHostConfig(hosts, field.NewPath("controlPlane").Child("hosts"))
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, but got %v", len(expected), errs)
+	}
+	for i, err := range errs {
+		if err.Field != expected[i] {
+			t.Errorf("expected error for %q, but got %q", expected[i], err.Field)
+		}
+	}
+}
+
 func TestValidateRegistryConfiguration(t *testing.T) {
 	tests := []struct {
 		name                  string
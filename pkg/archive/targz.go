@@ -19,6 +19,7 @@ package archive
 import (
 	"archive/tar"
 	"compress/gzip"
+	"io"
 	"os"
 
 	"github.com/pkg/errors"
@@ -30,6 +31,17 @@ type tarGzip struct {
 	arch *tar.Writer
 }
 
+// NewTarGzipWriter returns a new tar.gz archive written to the writer. The
+// writer is not closed with the archive.
+func NewTarGzipWriter(w io.Writer) Archive {
+	gz := gzip.NewWriter(w)
+
+	return &tarGzip{
+		gz:   gz,
+		arch: tar.NewWriter(gz),
+	}
+}
+
 // NewTarGzip returns a new tar.gz archive.
 func NewTarGzip(filename string) (Archive, error) {
 	f, err := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
@@ -17,9 +17,13 @@ limitations under the License.
 package certificate
 
 import (
+	"bytes"
+	"io/ioutil"
+
 	"github.com/pkg/errors"
 
 	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/credentials"
 	"k8c.io/kubeone/pkg/scripts"
 	"k8c.io/kubeone/pkg/ssh"
 	"k8c.io/kubeone/pkg/state"
@@ -49,7 +53,7 @@ func DownloadCA(s *state.State) error {
 		if s.BackupFile != "" {
 			s.Logger.Infoln("Creating local backup...")
 
-			err = s.Configuration.Backup(s.BackupFile)
+			err = backupPKI(s)
 			if err != nil {
 				// do not stop in case of failed backups, the user can
 				// always create the backup themselves if needed
@@ -60,3 +64,27 @@ func DownloadCA(s *state.State) error {
 		return nil
 	})
 }
+
+// backupPKI writes the backup of the downloaded PKI, holding the CA private
+// keys. The backup is encrypted like the credentials file if the credentials
+// passphrase is configured.
+func backupPKI(s *state.State) error {
+	var buf bytes.Buffer
+	if err := s.Configuration.Backup(&buf); err != nil {
+		return err
+	}
+	backup := buf.Bytes()
+
+	passphrase, err := credentials.ConfiguredPassphrase()
+	if err != nil {
+		return err
+	}
+	if len(passphrase) > 0 {
+		s.Logger.Infoln("Encrypting local backup with the credentials passphrase...")
+		if backup, err = credentials.Encrypt(backup, passphrase); err != nil {
+			return errors.Wrap(err, "failed to encrypt backup")
+		}
+	}
+
+	return errors.Wrap(ioutil.WriteFile(s.BackupFile, backup, 0600), "failed to write backup")
+}
@@ -28,6 +28,7 @@ import (
 
 	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
 	"k8c.io/kubeone/pkg/etcdutil"
+	"k8c.io/kubeone/pkg/runner"
 	"k8c.io/kubeone/pkg/ssh/sshtunnel"
 	"k8c.io/kubeone/pkg/state"
 )
@@ -73,7 +74,11 @@ func Get(s *state.State, node kubeoneapi.HostConfig, etcdRing *clientv3.MemberLi
 		return nil, err
 	}
 
-	etcdTLSConfig, err := etcdutil.LoadTLSConfig(sshconn)
+	etcdTLSConfig, err := etcdutil.LoadTLSConfig(&runner.Runner{
+		Conn:                sshconn,
+		PrivilegeEscalation: node.PrivilegeEscalation,
+		SudoPasswordFile:    node.SudoPasswordFile,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +121,7 @@ func applyCmd(rootFlags *pflag.FlagSet) *cobra.Command {
 		longFlagName(opts, "BackupFile"),
 		shortFlagName(opts, "BackupFile"),
 		"",
-		"path to where the PKI backup .tar.gz file should be placed (default: location of cluster config file)")
+		"path to where the PKI backup .tar.gz file should be placed (default: location of cluster config file). The backup is encrypted if the credentials passphrase is configured, and decrypted using \"kubeone credentials decrypt\"")
 
 	cmd.Flags().BoolVar(
 		&opts.NoInit,
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -32,9 +33,13 @@ import (
 
 	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
 	"k8c.io/kubeone/pkg/apis/kubeone/config"
+	"k8c.io/kubeone/pkg/apis/kubeone/jsonschema"
+	kubeonev1beta1 "k8c.io/kubeone/pkg/apis/kubeone/v1beta1"
 	"k8c.io/kubeone/pkg/templates/machinecontroller"
 	"k8c.io/kubeone/pkg/yamled"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	kyaml "sigs.k8s.io/yaml"
 )
 
@@ -90,6 +95,9 @@ func configCmd(rootFlags *pflag.FlagSet) *cobra.Command {
 	cmd.AddCommand(printCmd())
 	cmd.AddCommand(migrateCmd(rootFlags))
 	cmd.AddCommand(machinedeploymentsCmd(rootFlags))
+	cmd.AddCommand(schemaCmd())
+	cmd.AddCommand(validateCmd(rootFlags))
+	cmd.AddCommand(dumpCmd(rootFlags))
 
 	return cmd
 }
@@ -227,6 +235,130 @@ The manifest is printed on the standard output.
 	return cmd
 }
 
+type schemaOpts struct {
+	APIVersion string `longflag:"api-version"`
+}
+
+// schemaCmd setups the schema command
+func schemaCmd() *cobra.Command {
+	opts := &schemaOpts{}
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the KubeOneCluster manifest",
+		Long: heredoc.Doc(`
+			Print the JSON Schema of the KubeOneCluster manifest, generated from the
+			API types of the given API version.
+
+			The schema can be used by editors supporting the YAML language server to
+			validate and complete the KubeOneCluster manifests.
+		`),
+		Args:    cobra.ExactArgs(0),
+		Example: `kubeone config schema --api-version kubeone.io/v1beta1 > kubeone-schema.json`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runSchema(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(
+		&opts.APIVersion,
+		longFlagName(opts, "APIVersion"),
+		kubeonev1beta1.SchemeGroupVersion.String(),
+		"API version of the KubeOneCluster manifest")
+
+	return cmd
+}
+
+const (
+	validateOutputText = "text"
+	validateOutputJSON = "json"
+)
+
+// errInvalidManifest is returned by the validate command when the manifest is
+// invalid, after the validation result was printed
+var errInvalidManifest = errors.New("the KubeOneCluster manifest is invalid")
+
+type validateOpts struct {
+	globalOptions
+	Output string `longflag:"output" shortflag:"o"`
+}
+
+// validateCmd setups the validate command
+func validateCmd(rootFlags *pflag.FlagSet) *cobra.Command {
+	opts := &validateOpts{}
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the KubeOneCluster manifest",
+		Long: heredoc.Doc(`
+			Validate the KubeOneCluster manifest without connecting to the cluster hosts.
+
+			The manifest is loaded, merged with the Terraform output and the credentials file, defaulted and validated
+			the same way as by the other commands, and all validation errors are printed along with the paths of the
+			invalid fields. Using '--output json', the result is printed as the JSON object. The command exits with
+			the exit code 1 if the manifest is invalid.
+		`),
+		Args: cobra.ExactArgs(0),
+		Example: heredoc.Doc(`
+			kubeone config validate -m mycluster.yaml -t terraformoutput.json
+			kubeone config validate -m mycluster.yaml -t terraformoutput.json --output json
+		`),
+		// the errors are printed by Execute, and the standard output must
+		// hold only the JSON document when using the JSON output
+		SilenceErrors: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			gopts, err := persistentGlobalOptions(rootFlags)
+			if err != nil {
+				return errors.Wrap(err, "unable to get global flags")
+			}
+			opts.globalOptions = *gopts
+
+			return runValidate(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(
+		&opts.Output,
+		longFlagName(opts, "Output"),
+		shortFlagName(opts, "Output"),
+		validateOutputText,
+		fmt.Sprintf("output format, one of %q or %q", validateOutputText, validateOutputJSON))
+
+	return cmd
+}
+
+// dumpCmd setups the dump command
+func dumpCmd(rootFlags *pflag.FlagSet) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Print the fully resolved KubeOneCluster manifest",
+		Long: heredoc.Doc(`
+			Print the KubeOneCluster manifest resolved from the given manifest, the inventory, the Terraform output
+			and the defaults, in the latest API version, as it's used by the other commands.
+
+			When the manifest is layered, either by repeating the --manifest flag or by listing the "bases" in the
+			manifest, the manifests are merged in order, and the merged values are sourced from the "manifest".
+
+			Every value is commented with its source, which is either "manifest", "inventory", "flag", "terraform" or "default".
+			Credentials, such as the cloud-config, the proxy URLs and the cloud provider credentials, are redacted,
+			including the values interpolated into them from the environment and files.
+		`),
+		Args: cobra.ExactArgs(0),
+		Example: heredoc.Doc(`
+			kubeone config dump -m mycluster.yaml -t terraformoutput.json
+			kubeone config dump -m base.yaml -m production.yaml -t terraformoutput.json
+		`),
+		RunE: func(_ *cobra.Command, args []string) error {
+			gopts, err := persistentGlobalOptions(rootFlags)
+			if err != nil {
+				return errors.Wrap(err, "unable to get global flags")
+			}
+
+			return runDump(gopts)
+		},
+	}
+
+	return cmd
+}
+
 // runPrint prints an example configuration file
 func runPrint(printOptions *printOpts) error {
 	if printOptions.FullConfig {
@@ -430,6 +562,10 @@ func parseControlPlaneHosts(cfg *yamled.Document, hostList string) error {
 
 // runMigrate migrates the KubeOneCluster manifest from v1alpha1 to v1beta1
 func runMigrate(opts *globalOptions) error {
+	if len(opts.ManifestFiles) > 1 {
+		return errors.New("only a single manifest can be migrated")
+	}
+
 	// Convert old config yaml to new config yaml
 	newConfigYAML, err := config.MigrateOldConfig(opts.ManifestFile)
 	if err != nil {
@@ -461,6 +597,120 @@ func runGenerateMachineDeployments(opts *globalOptions) error {
 	return nil
 }
 
+// runSchema prints the JSON Schema of the KubeOneCluster manifest
+func runSchema(opts *schemaOpts) error {
+	s, err := jsonschema.Generate(opts.APIVersion)
+	if err != nil {
+		return errors.Wrap(err, "unable to generate the schema")
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return errors.Wrap(encoder.Encode(s), "failed to encode the schema")
+}
+
+// validationError is the validation error in the JSON output of the validate command
+type validationError struct {
+	// Field is the path to the invalid field, empty if the error is not
+	// related to a field, e.g. the manifest can't be parsed
+	Field  string      `json:"field,omitempty"`
+	Type   string      `json:"type,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+	Detail string      `json:"detail"`
+}
+
+type validationResult struct {
+	Valid  bool              `json:"valid"`
+	Errors []validationError `json:"errors"`
+}
+
+// runValidate loads and validates the KubeOneCluster manifest
+func runValidate(opts *validateOpts) error {
+	if opts.Output != validateOutputText && opts.Output != validateOutputJSON {
+		return errors.Errorf("invalid output format %q, must be %q or %q", opts.Output, validateOutputText, validateOutputJSON)
+	}
+
+	_, err := config.LoadKubeOneCluster(
+		opts.ManifestFiles,
+		opts.TerraformState,
+		opts.CredentialsFile,
+		newLogger(opts.Verbose),
+		config.AllowUnknownFields(opts.AllowUnknownFields),
+		config.Inventory(opts.Inventory),
+		config.CredentialsExec(opts.CredentialsExec))
+
+	result := validationResult{
+		Valid:  err == nil,
+		Errors: []validationError{},
+	}
+
+	var fieldErrs []error
+	if agg, ok := errors.Cause(err).(utilerrors.Aggregate); ok {
+		fieldErrs = agg.Errors()
+	} else if err != nil {
+		fieldErrs = []error{err}
+	}
+
+	for _, e := range fieldErrs {
+		if fieldErr, ok := e.(*field.Error); ok {
+			result.Errors = append(result.Errors, validationError{
+				Field:  fieldErr.Field,
+				Type:   string(fieldErr.Type),
+				Value:  fieldErr.BadValue,
+				Detail: fieldErr.Detail,
+			})
+			continue
+		}
+		result.Errors = append(result.Errors, validationError{Detail: e.Error()})
+	}
+
+	if opts.Output == validateOutputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(result); err != nil {
+			return errors.Wrap(err, "failed to encode the validation result")
+		}
+
+		if !result.Valid {
+			return errInvalidManifest
+		}
+
+		return nil
+	}
+
+	if result.Valid {
+		fmt.Println("The KubeOneCluster manifest is valid")
+		return nil
+	}
+
+	for _, e := range fieldErrs {
+		fmt.Println(e)
+	}
+	fmt.Printf("The KubeOneCluster manifest is invalid, found %d error(s)\n", len(fieldErrs))
+
+	return errInvalidManifest
+}
+
+// runDump prints the fully resolved KubeOneCluster manifest
+func runDump(opts *globalOptions) error {
+	manifest, err := config.DumpKubeOneCluster(
+		opts.ManifestFiles,
+		opts.TerraformState,
+		opts.CredentialsFile,
+		newLogger(opts.Verbose),
+		config.AllowUnknownFields(opts.AllowUnknownFields),
+		config.Inventory(opts.Inventory),
+		config.CredentialsExec(opts.CredentialsExec))
+	if err != nil {
+		return errors.Wrap(err, "unable to resolve the KubeOneCluster manifest")
+	}
+
+	_, err = os.Stdout.Write(manifest)
+
+	return errors.WithStack(err)
+}
+
 func validateAndPrintConfig(cfgYaml interface{}) error {
 	// Validate new config by unmarshaling
 	var buffer bytes.Buffer
@@ -563,6 +813,8 @@ cloudProvider:
   # more details.
   csiMigrationComplete: false
   # Path to file that will be uploaded and used as custom '--cloud-config' file.
+  # String values can be read from files, relative to this manifest, and from
+  # the environment, e.g. "${file:cloud.conf}" or "${env:CLOUD_CONFIG}".
   cloudConfig: "{{ .CloudProviderCloudCfg }}"
 
 # Controls which container runtime will be installed on instances.
@@ -775,7 +1027,7 @@ registryConfiguration:
 addons:
   enable: false
   # In case when the relative path is provided, the path is relative
-  # to the KubeOne configuration file.
+  # to the KubeOne configuration file declaring it.
   path: "./addons"
 
 # The list of nodes can be overwritten by providing Terraform output.
@@ -786,16 +1038,64 @@ addons:
 #   hosts:
 #   - publicAddress: '1.2.3.4'
 #     privateAddress: '172.18.0.1'
+#     # Commands can be run directly on the machine KubeOne is running on,
+#     # without SSH, by using the local connection (default is 'ssh').
+#     # connection: 'local'
 #     bastion: '4.3.2.1'
 #     bastionPort: 22  # can be left out if using the default (22)
 #     bastionUser: 'root'  # can be left out if using the default ('root')
+#     # Alternatively to bastion, a chain of bastion hosts can be used to
+#     # reach the host through multiple hops (similar to ProxyJump).
+#     # Credentials of the host are used for a bastion host that doesn't
+#     # specify sshPrivateKeyFile or sshAgentSocket.
+#     # bastions:
+#     # - address: 'jump.example.com'
+#     #   port: 22
+#     #   user: 'jump'
+#     # - address: '10.0.0.1'
+#     #   sshPrivateKeyFile: '/home/me/.ssh/bastion_rsa'
 #     sshPort: 22 # can be left out if using the default (22)
 #     sshUsername: root
 #     # You usually want to configure either a private key OR an
 #     # agent socket, but never both. The socket value can be
 #     # prefixed with "env:" to refer to an environment variable.
 #     sshPrivateKeyFile: '/home/me/.ssh/id_rsa'
+#     # Encrypted private keys are decrypted using the passphrase from
+#     # the given file, the KUBEONE_SSH_KEY_PASSPHRASE environment variable,
+#     # or the passphrase is asked for interactively.
+#     # sshPrivateKeyPassphraseFile: '/home/me/.ssh/id_rsa.passphrase'
+#     # OpenSSH user certificate signed for the private key, or for a key
+#     # held by the SSH agent. The id_rsa-cert.pub file next to the private
+#     # key is used by default, if it exists.
+#     # sshCertificateFile: '/home/me/.ssh/id_rsa-cert.pub'
 #     sshAgentSocket: 'env:SSH_AUTH_SOCK'
+#     # Host keys of the host and the bastion host are verified according
+#     # to sshHostKeyCheck:
+#     # * Ignore - host keys are not verified (default)
+#     # * Strict - host keys must be present in sshKnownHostsFile
+#     # * TrustOnFirstUse - unknown host keys are recorded in sshKnownHostsFile
+#     #   and mismatching host keys are rejected
+#     sshHostKeyCheck: 'Strict'
+#     sshKnownHostsFile: '~/.ssh/known_hosts'
+#     # Host keys can also be pinned, in which case the presented host key
+#     # must match the pinned one.
+#     # sshHostPublicKey: 'ssh-ed25519 AAAA...'
+#     # bastionHostPublicKey: 'ssh-ed25519 AAAA...'
+#     # If sshConfigFile is set, publicAddress is used as the host alias in
+#     # the OpenSSH client configuration, and HostName, User, Port,
+#     # IdentityFile, CertificateFile and ProxyJump from it are used unless
+#     # configured here. privateAddress must then be set to the IP address.
+#     # sshConfigFile: '~/.ssh/config'
+#     # Files are transferred using SFTP, falling back to streaming them over
+#     # commands run on the host if the SFTP subsystem is disabled. Possible
+#     # values are 'Auto' (default), 'SFTP' and 'Exec'.
+#     # sshFileTransfer: 'Exec'
+#     # Commands are run as root using passwordless sudo by default. Possible
+#     # values are 'none' (e.g. when logging in as root), 'sudo',
+#     # 'sudo-password' and 'doas'. The sudo password is read from the
+#     # given file, or the KUBEONE_SUDO_PASSWORD environment variable.
+#     # privilegeEscalation: 'sudo-password'
+#     # sudoPasswordFile: '/home/me/.sudo-password'
 #     # Taints is used to apply taints to the node.
 #     # If not provided defaults to TaintEffectNoSchedule, with key
 #     # node-role.kubernetes.io/master for control plane nodes.
@@ -803,6 +1103,14 @@ addons:
 #     taints:
 #     - key: "node-role.kubernetes.io/master"
 #       effect: "NoSchedule"
+#     # Labels and annotations are applied to the node, in addition to the
+#     # labels and annotations of all control plane nodes. Labels, annotations
+#     # and taints are reconciled on every apply, removing only the ones
+#     # previously applied by KubeOne.
+#     # labels:
+#     #   example.com/zone: 'zone-a'
+#     # annotations:
+#     #   example.com/owner: 'team-a'
 
 # A list of static workers, not managed by MachineController.
 # The list of nodes can be overwritten by providing Terraform output.
@@ -810,21 +1118,75 @@ addons:
 #   hosts:
 #   - publicAddress: '1.2.3.5'
 #     privateAddress: '172.18.0.2'
+#     # Commands can be run directly on the machine KubeOne is running on,
+#     # without SSH, by using the local connection (default is 'ssh').
+#     # connection: 'local'
 #     bastion: '4.3.2.1'
 #     bastionPort: 22  # can be left out if using the default (22)
 #     bastionUser: 'root'  # can be left out if using the default ('root')
+#     # Alternatively to bastion, a chain of bastion hosts can be used to
+#     # reach the host through multiple hops (similar to ProxyJump).
+#     # Credentials of the host are used for a bastion host that doesn't
+#     # specify sshPrivateKeyFile or sshAgentSocket.
+#     # bastions:
+#     # - address: 'jump.example.com'
+#     #   port: 22
+#     #   user: 'jump'
+#     # - address: '10.0.0.1'
+#     #   sshPrivateKeyFile: '/home/me/.ssh/bastion_rsa'
 #     sshPort: 22 # can be left out if using the default (22)
 #     sshUsername: root
 #     # You usually want to configure either a private key OR an
 #     # agent socket, but never both. The socket value can be
 #     # prefixed with "env:" to refer to an environment variable.
 #     sshPrivateKeyFile: '/home/me/.ssh/id_rsa'
+#     # Encrypted private keys are decrypted using the passphrase from
+#     # the given file, the KUBEONE_SSH_KEY_PASSPHRASE environment variable,
+#     # or the passphrase is asked for interactively.
+#     # sshPrivateKeyPassphraseFile: '/home/me/.ssh/id_rsa.passphrase'
+#     # OpenSSH user certificate signed for the private key, or for a key
+#     # held by the SSH agent. The id_rsa-cert.pub file next to the private
+#     # key is used by default, if it exists.
+#     # sshCertificateFile: '/home/me/.ssh/id_rsa-cert.pub'
 #     sshAgentSocket: 'env:SSH_AUTH_SOCK'
+#     # Host keys of the host and the bastion host are verified according
+#     # to sshHostKeyCheck:
+#     # * Ignore - host keys are not verified (default)
+#     # * Strict - host keys must be present in sshKnownHostsFile
+#     # * TrustOnFirstUse - unknown host keys are recorded in sshKnownHostsFile
+#     #   and mismatching host keys are rejected
+#     sshHostKeyCheck: 'Strict'
+#     sshKnownHostsFile: '~/.ssh/known_hosts'
+#     # Host keys can also be pinned, in which case the presented host key
+#     # must match the pinned one.
+#     # sshHostPublicKey: 'ssh-ed25519 AAAA...'
+#     # bastionHostPublicKey: 'ssh-ed25519 AAAA...'
+#     # If sshConfigFile is set, publicAddress is used as the host alias in
+#     # the OpenSSH client configuration, and HostName, User, Port,
+#     # IdentityFile, CertificateFile and ProxyJump from it are used unless
+#     # configured here. privateAddress must then be set to the IP address.
+#     # sshConfigFile: '~/.ssh/config'
+#     # Files are transferred using SFTP, falling back to streaming them over
+#     # commands run on the host if the SFTP subsystem is disabled. Possible
+#     # values are 'Auto' (default), 'SFTP' and 'Exec'.
+#     # sshFileTransfer: 'Exec'
+#     # Commands are run as root using passwordless sudo by default. Possible
+#     # values are 'none' (e.g. when logging in as root), 'sudo',
+#     # 'sudo-password' and 'doas'. The sudo password is read from the
+#     # given file, or the KUBEONE_SUDO_PASSWORD environment variable.
+#     # privilegeEscalation: 'sudo-password'
+#     # sudoPasswordFile: '/home/me/.sudo-password'
 #     # Taints is used to apply taints to the node.
 #     # Explicitly empty (i.e. taints: {}) means no taints will be applied.
 #     # taints:
 #     # - key: ""
 #     #   effect: ""
+#     # labels:
+#     #   example.com/zone: 'zone-a'
+#   # Labels and annotations applied to all static worker nodes.
+#   # labels:
+#   #   node-role.kubernetes.io/worker: ''
+#   # annotations: {}
 
 # The API server can also be overwritten by Terraform. Provide the
 # external address of your load balancer or the public addresses of
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"k8c.io/kubeone/pkg/credentials"
+)
+
+type credentialsCryptOpts struct {
+	PassphraseFile string `longflag:"passphrase-file"`
+	Output         string `longflag:"output" shortflag:"o"`
+}
+
+// credentialsCmd setups the credentials command
+func credentialsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credentials",
+		Short: "Commands for working with the credentials files",
+		Long: heredoc.Docf(`
+			Commands for working with the credentials files.
+
+			The credentials file can be encrypted with a passphrase, so it can be committed to git. The encrypted
+			file is used in place of the plaintext file with the --credentials flag, and it's decrypted in memory.
+			The passphrase is read from the key file set in the %s environment variable,
+			from the %s environment variable, or asked for if running in the terminal.
+
+			The PKI backup, created by the install and apply commands, is encrypted with the same passphrase if it's
+			set in the environment or used to decrypt the credentials file. The encrypted backup is decrypted by
+			the decrypt command.
+		`, credentials.PassphraseFileEnvVar, credentials.PassphraseEnvVar),
+	}
+
+	cmd.AddCommand(credentialsEncryptCmd())
+	cmd.AddCommand(credentialsDecryptCmd())
+
+	return cmd
+}
+
+func credentialsEncryptCmd() *cobra.Command {
+	opts := &credentialsCryptOpts{}
+	cmd := &cobra.Command{
+		Use:   "encrypt <credentials-file>",
+		Short: "Encrypt the credentials file",
+		Long: heredoc.Doc(`
+			Encrypt the credentials file with the key derived from the passphrase. The encrypted file is printed
+			on the standard output, unless the output file is given.
+		`),
+		Args:    cobra.ExactArgs(1),
+		Example: `kubeone credentials encrypt credentials.yaml --output credentials.enc.yaml`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runCredentialsEncrypt(args[0], opts)
+		},
+	}
+
+	addCredentialsCryptFlags(cmd, opts)
+
+	return cmd
+}
+
+func credentialsDecryptCmd() *cobra.Command {
+	opts := &credentialsCryptOpts{}
+	cmd := &cobra.Command{
+		Use:   "decrypt <encrypted-credentials-file>",
+		Short: "Decrypt the credentials file",
+		Long: heredoc.Doc(`
+			Decrypt the credentials file encrypted by the encrypt command, or the encrypted PKI backup. The
+			decrypted file is printed on the standard output, unless the output file is given.
+		`),
+		Args:    cobra.ExactArgs(1),
+		Example: `kubeone credentials decrypt credentials.enc.yaml --passphrase-file ~/.kubeone/passphrase`,
+		RunE: func(_ *cobra.Command, args []string) error {
+			return runCredentialsDecrypt(args[0], opts)
+		},
+	}
+
+	addCredentialsCryptFlags(cmd, opts)
+
+	return cmd
+}
+
+func addCredentialsCryptFlags(cmd *cobra.Command, opts *credentialsCryptOpts) {
+	cmd.Flags().StringVar(
+		&opts.PassphraseFile,
+		longFlagName(opts, "PassphraseFile"),
+		"",
+		fmt.Sprintf("key file holding the passphrase, overriding the %s environment variable", credentials.PassphraseFileEnvVar))
+
+	cmd.Flags().StringVarP(
+		&opts.Output,
+		longFlagName(opts, "Output"),
+		shortFlagName(opts, "Output"),
+		"",
+		"file to write the result to, instead of the standard output")
+}
+
+// runCredentialsEncrypt encrypts the credentials file
+func runCredentialsEncrypt(credentialsFilePath string, opts *credentialsCryptOpts) error {
+	plaintext, err := ioutil.ReadFile(credentialsFilePath)
+	if err != nil {
+		return errors.Wrap(err, "unable to read the credentials file")
+	}
+
+	if credentials.IsEncrypted(plaintext) {
+		return errors.Errorf("credentials file %q is already encrypted", credentialsFilePath)
+	}
+
+	// catch the malformed files before they're hidden by the encryption
+	if err = yaml.Unmarshal(plaintext, &map[string]string{}); err != nil {
+		return errors.Wrap(err, "unable to unmarshal credentials file")
+	}
+
+	passphrase, err := credentials.ReadPassphrase(opts.PassphraseFile, "Enter passphrase: ", true)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := credentials.Encrypt(plaintext, passphrase)
+	if err != nil {
+		return errors.Wrap(err, "unable to encrypt the credentials file")
+	}
+
+	return writeCredentialsOutput(encrypted, opts.Output)
+}
+
+// runCredentialsDecrypt decrypts the credentials file
+func runCredentialsDecrypt(credentialsFilePath string, opts *credentialsCryptOpts) error {
+	encrypted, err := ioutil.ReadFile(credentialsFilePath)
+	if err != nil {
+		return errors.Wrap(err, "unable to read the credentials file")
+	}
+
+	if !credentials.IsEncrypted(encrypted) {
+		return errors.Errorf("credentials file %q is not encrypted", credentialsFilePath)
+	}
+
+	passphrase, err := credentials.ReadPassphrase(opts.PassphraseFile, "Enter passphrase: ", false)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := credentials.Decrypt(encrypted, passphrase)
+	if err != nil {
+		return errors.Wrapf(err, "unable to decrypt credentials file %q", credentialsFilePath)
+	}
+
+	return writeCredentialsOutput(plaintext, opts.Output)
+}
+
+func writeCredentialsOutput(content []byte, output string) error {
+	if output == "" {
+		_, err := os.Stdout.Write(content)
+		return errors.WithStack(err)
+	}
+
+	return errors.Wrap(ioutil.WriteFile(output, content, 0600), "unable to write the output file")
+}
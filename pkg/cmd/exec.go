@@ -0,0 +1,181 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/koron-go/prefixw"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh"
+	"k8c.io/kubeone/pkg/state"
+	"k8c.io/kubeone/pkg/tabwriter"
+)
+
+const (
+	execSelectorControlPlane = "controlplane"
+	execSelectorWorkers      = "workers"
+	execSelectorHostPrefix   = "host="
+)
+
+type execOpts struct {
+	globalOptions
+	Selector string `longflag:"selector" shortflag:"s"`
+}
+
+// execCmd returns the structure for declaring the "exec" subcommand.
+func execCmd(rootFlags *pflag.FlagSet) *cobra.Command {
+	opts := &execOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "exec [flags] -- <command>",
+		Short: "Run the command on the cluster hosts",
+		Long: heredoc.Doc(`
+			Run the command on the cluster hosts.
+
+			The command is run in parallel on all hosts, or on the hosts chosen by the selector, which is one of:
+			"controlplane", "workers" (static worker hosts) or "host=<hostname|address|index>". The output of the command is
+			prefixed with the host address, and the exit codes of the command on each host are summarized at the end.
+
+			The command is run as the SSH user. Privileged commands should use sudo, which is substituted with the
+			privilege escalation configured for the host.
+		`),
+		Example: heredoc.Doc(`
+			kubeone exec -m mycluster.yaml -t terraformoutput.json -- uptime
+			kubeone exec -m mycluster.yaml -t terraformoutput.json --selector controlplane -- sudo crictl ps
+		`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			gopts, err := persistentGlobalOptions(rootFlags)
+			if err != nil {
+				return errors.Wrap(err, "unable to get global flags")
+			}
+			opts.globalOptions = *gopts
+
+			return runExec(opts, strings.Join(args, " "))
+		},
+	}
+
+	cmd.Flags().StringVarP(
+		&opts.Selector,
+		longFlagName(opts, "Selector"),
+		shortFlagName(opts, "Selector"),
+		"",
+		fmt.Sprintf("hosts to run the command on, one of: %s, %s, %s<hostname|address|index> (default all hosts)",
+			execSelectorControlPlane, execSelectorWorkers, execSelectorHostPrefix))
+
+	return cmd
+}
+
+func runExec(opts *execOpts, command string) error {
+	s, err := opts.BuildState()
+	if err != nil {
+		return err
+	}
+
+	hosts, err := selectHosts(s.Cluster, opts.Selector)
+	if err != nil {
+		return err
+	}
+	if len(hosts) == 0 {
+		return errors.Errorf("no hosts match the selector %q", opts.Selector)
+	}
+
+	var (
+		mu        sync.Mutex
+		exitCodes = map[int]int{}
+	)
+
+	// the error is not returned, as hosts which failed are reported in the
+	// summary below
+	_ = s.RunTaskOnNodes(hosts, func(s *state.State, node *kubeoneapi.HostConfig, _ ssh.Connection) error {
+		stdout := prefixw.New(os.Stdout, s.Runner.Prefix)
+		stderr := prefixw.New(os.Stderr, s.Runner.Prefix)
+
+		exitCode, err := s.Runner.Stream(command, stdout, stderr)
+
+		mu.Lock()
+		exitCodes[node.ID] = exitCode
+		mu.Unlock()
+
+		if exitCode > 0 {
+			// non-zero exit code is reported in the summary
+			return nil
+		}
+
+		return err
+	}, state.RunParallel)
+
+	fmt.Println()
+
+	printer := tabwriter.GetNewTabWriter(os.Stdout)
+	fmt.Fprintln(printer, "HOST\tEXIT CODE\t")
+
+	failed := 0
+	for _, host := range hosts {
+		exitCode, ok := exitCodes[host.ID]
+
+		result := strconv.Itoa(exitCode)
+		if !ok || exitCode < 0 {
+			result = "failed"
+		}
+		if result != "0" {
+			failed++
+		}
+
+		fmt.Fprintf(printer, "%s\t%s\t\n", host.PublicAddress, result)
+	}
+	printer.Flush()
+
+	if failed > 0 {
+		return errors.Errorf("command failed on %d of %d host(s)", failed, len(hosts))
+	}
+
+	return nil
+}
+
+// selectHosts returns the hosts matching the selector, or all hosts if the
+// selector is empty
+func selectHosts(cluster *kubeoneapi.KubeOneCluster, selector string) ([]kubeoneapi.HostConfig, error) {
+	switch {
+	case selector == "":
+		return allHosts(cluster), nil
+	case selector == execSelectorControlPlane:
+		return cluster.ControlPlane.Hosts, nil
+	case selector == execSelectorWorkers:
+		return cluster.StaticWorkers.Hosts, nil
+	case strings.HasPrefix(selector, execSelectorHostPrefix):
+		host, err := findHost(cluster, strings.TrimPrefix(selector, execSelectorHostPrefix))
+		if err != nil {
+			return nil, err
+		}
+
+		return []kubeoneapi.HostConfig{host}, nil
+	}
+
+	return nil, errors.Errorf("invalid selector %q, must be one of: %s, %s, %s<hostname|address|index>",
+		selector, execSelectorControlPlane, execSelectorWorkers, execSelectorHostPrefix)
+}
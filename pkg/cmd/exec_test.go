@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectHosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		selector    string
+		expectedIDs []int
+		expectedErr bool
+	}{
+		{
+			name:        "all hosts",
+			selector:    "",
+			expectedIDs: []int{0, 1, 2},
+		},
+		{
+			name:        "control plane",
+			selector:    "controlplane",
+			expectedIDs: []int{0, 1},
+		},
+		{
+			name:        "workers",
+			selector:    "workers",
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "host by hostname",
+			selector:    "host=cp-0",
+			expectedIDs: []int{0},
+		},
+		{
+			name:        "host by index",
+			selector:    "host=2",
+			expectedIDs: []int{2},
+		},
+		{
+			name:        "ambiguous host",
+			selector:    "host=10.0.0.2",
+			expectedErr: true,
+		},
+		{
+			name:        "host index out of range",
+			selector:    "host=5",
+			expectedErr: true,
+		},
+		{
+			name:        "empty host",
+			selector:    "host=",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid selector",
+			selector:    "masters",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, err := selectHosts(testHostsCluster, tt.selector)
+			if tt.expectedErr {
+				if err == nil {
+					t.Fatal("expected error, but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ids := []int{}
+			for _, host := range hosts {
+				ids = append(ids, host.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("expected hosts %v, but got %v", tt.expectedIDs, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/proxy"
+	"k8c.io/kubeone/pkg/ssh/sshtunnel"
+)
+
+type portForwardOpts struct {
+	globalOptions
+	Address string `longflag:"address"`
+	Via     string `longflag:"via"`
+}
+
+// portForwardCmd returns the structure for declaring the "port-forward" subcommand.
+func portForwardCmd(rootFlags *pflag.FlagSet) *cobra.Command {
+	opts := &portForwardOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "port-forward <local-port>:<host>:<port> [<local-port>:<host>:<port>...]",
+		Short: "Forward local ports to addresses reachable from the cluster hosts",
+		Long: heredoc.Doc(`
+			Forward local ports to addresses reachable from the cluster hosts, using SSH tunnel.
+
+			This command helps to reach services on private networks, such as etcd metrics, kubelet, or NodePort services.
+			The host is resolved and connected to from the host the tunnel goes through, so "localhost" refers to the
+			services on that host. IPv6 hosts must be enclosed in square brackets.
+
+			The tunnel goes through one of the control-plane hosts, and if it fails, another control-plane host is used.
+			The host can be chosen by its hostname, public or private address, or its index, using the '--via' flag.
+		`),
+		Example: heredoc.Doc(`
+			kubeone port-forward -m mycluster.yaml -t terraformoutput.json 2381:localhost:2381 10250:10.0.0.12:10250
+			kubeone port-forward -m mycluster.yaml -t terraformoutput.json --via 2 9100:localhost:9100
+		`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			gopts, err := persistentGlobalOptions(rootFlags)
+			if err != nil {
+				return errors.Wrap(err, "unable to get global flags")
+			}
+			opts.globalOptions = *gopts
+
+			return runPortForward(opts, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Address, longFlagName(opts, "Address"), "127.0.0.1", "local address to listen on")
+	cmd.Flags().StringVar(&opts.Via, longFlagName(opts, "Via"), "", "hostname, address or index of the host to tunnel through (default any control-plane host)")
+
+	return cmd
+}
+
+func runPortForward(opts *portForwardOpts, specs []string) error {
+	forwards := []proxy.Forward{}
+	for _, spec := range specs {
+		fwd, err := proxy.ParseForward(spec)
+		if err != nil {
+			return err
+		}
+		forwards = append(forwards, fwd)
+	}
+
+	s, err := opts.BuildState()
+	if err != nil {
+		return err
+	}
+
+	hosts := s.Cluster.ControlPlane.Hosts
+	if opts.Via != "" {
+		host, err := findHost(s.Cluster, opts.Via)
+		if err != nil {
+			return err
+		}
+		hosts = []kubeoneapi.HostConfig{host}
+	}
+
+	dialer, err := sshtunnel.NewFailoverDialer(s.Connector, hosts, s.Logger)
+	if err != nil {
+		return err
+	}
+
+	if err = dialer.Connect(); err != nil {
+		return err
+	}
+
+	listeners := []net.Listener{}
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+
+	for _, fwd := range forwards {
+		l, err := net.Listen("tcp", net.JoinHostPort(opts.Address, strconv.Itoa(fwd.LocalPort)))
+		if err != nil {
+			return errors.Wrap(err, "failed to listen")
+		}
+		listeners = append(listeners, l)
+	}
+
+	errCh := make(chan error, len(forwards))
+	for i, fwd := range forwards {
+		fmt.Printf("Forwarding %s -> %s\n", listeners[i].Addr(), fwd.RemoteAddr)
+
+		go func(l net.Listener, remoteAddr string) {
+			errCh <- proxy.ServeForward(s.Context, l, dialer.DialContext, remoteAddr, s.Logger)
+		}(listeners[i], fwd.RemoteAddr)
+	}
+
+	return <-errCh
+}
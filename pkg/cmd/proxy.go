@@ -18,7 +18,7 @@ package cmd
 
 import (
 	"fmt"
-	"io"
+	"net"
 	"net/http"
 
 	"github.com/MakeNowJust/heredoc/v2"
@@ -26,13 +26,19 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
-	"k8c.io/kubeone/pkg/ssh"
-	"k8c.io/kubeone/pkg/state"
+	"k8c.io/kubeone/pkg/proxy"
+	"k8c.io/kubeone/pkg/ssh/sshtunnel"
+)
+
+const (
+	proxyModeHTTP   = "http"
+	proxyModeSOCKS5 = "socks5"
 )
 
 type proxyOpts struct {
 	globalOptions
 	ListenAddr string `longflag:"listen"`
+	Mode       string `longflag:"mode"`
 }
 
 func proxyCmd(rootFlags *pflag.FlagSet) *cobra.Command {
@@ -42,13 +48,19 @@ func proxyCmd(rootFlags *pflag.FlagSet) *cobra.Command {
 		Use:   "proxy",
 		Short: "Proxy to the kube-apiserver using SSH tunnel",
 		Long: heredoc.Doc(`
-			HTTPS Proxy (CONNECT method) SSH tunnel.
+			HTTP or SOCKS5 proxy over SSH tunnel.
 
 			This command helps to reach kubeapi endpoint with local kubectl in case when private/firewalled endpoint is used (e.g.
 			internal loadbalancer). It creates SSH tunnel to one of the control-plane nodes and then proxies incomming requests
-			through it.
+			through it. If the tunnel fails, another control-plane node is used.
+
+			The HTTP proxy (default) tunnels HTTPS requests (CONNECT method) and forwards plain HTTP requests. The SOCKS5 proxy
+			supports the CONNECT command without authentication.
+		`),
+		Example: heredoc.Doc(`
+			kubeone proxy -m mycluster.yaml -t terraformoutput.json
+			kubeone proxy -m mycluster.yaml -t terraformoutput.json --mode socks5 --listen 127.0.0.1:1080
 		`),
-		Example: `kubeone proxy -m mycluster.yaml -t terraformoutput.json`,
 		RunE: func(*cobra.Command, []string) error {
 			gopts, err := persistentGlobalOptions(rootFlags)
 			if err != nil {
@@ -60,94 +72,51 @@ func proxyCmd(rootFlags *pflag.FlagSet) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&opts.ListenAddr, longFlagName(opts, "ListenAddr"), "127.0.0.1:8888", "SSH tunnel HTTP proxy bind address")
+	cmd.Flags().StringVar(&opts.ListenAddr, longFlagName(opts, "ListenAddr"), "127.0.0.1:8888", "SSH tunnel proxy bind address")
+	cmd.Flags().StringVar(&opts.Mode, longFlagName(opts, "Mode"), proxyModeHTTP, fmt.Sprintf("proxy protocol, one of: %s, %s", proxyModeHTTP, proxyModeSOCKS5))
 
 	return cmd
 }
 
 func setupProxyTunnel(opts *proxyOpts) error {
+	if opts.Mode != proxyModeHTTP && opts.Mode != proxyModeSOCKS5 {
+		return errors.Errorf("unknown proxy mode %q, must be one of: %s, %s", opts.Mode, proxyModeHTTP, proxyModeSOCKS5)
+	}
+
 	s, err := opts.BuildState()
 	if err != nil {
 		return err
 	}
 
-	// Check if we can authenticate via ssh
-	tunn, err := s.Connector.Tunnel(s.Cluster.RandomHost())
+	dialer, err := sshtunnel.NewFailoverDialer(s.Connector, s.Cluster.ControlPlane.Hosts, s.Logger)
 	if err != nil {
 		return err
 	}
-	defer tunn.Close()
 
-	server := &http.Server{
-		Addr: opts.ListenAddr,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if r.Method != http.MethodConnect {
-				http.Error(w, "bad request", http.StatusBadRequest)
-				return
-			}
+	// Check if we can authenticate via ssh
+	if err = dialer.Connect(); err != nil {
+		return err
+	}
 
-			if terr := handleTunneling(w, r, s, tunn); terr != nil {
-				code := http.StatusInternalServerError
-				if err1, ok := err.(*httpError); ok {
-					code = err1.code
-				}
-				http.Error(w, err.Error(), code)
-			}
-		}),
+	listener, err := net.Listen("tcp", opts.ListenAddr)
+	if err != nil {
+		return errors.Wrap(err, "failed to listen")
 	}
+	defer listener.Close()
 
 	fmt.Println("SSH tunnel started, please open another terminal and setup environment")
-	fmt.Printf("export HTTPS_PROXY=http://%s\n", opts.ListenAddr)
 
-	return server.ListenAndServe()
-}
-
-type httpError struct {
-	err  error
-	code int
-}
+	if opts.Mode == proxyModeSOCKS5 {
+		fmt.Printf("export HTTPS_PROXY=socks5://%s\n", listener.Addr())
 
-func (e *httpError) Error() string {
-	return fmt.Sprintf("error: %s, code: %d", e.err, e.code)
-}
-
-func handleTunneling(w http.ResponseWriter, r *http.Request, s *state.State, tunn ssh.Tunneler) error {
-	destConn, err := tunn.TunnelTo(s.Context, "tcp4", r.Host)
-	if err != nil {
-		tunn.Close()
-		return &httpError{err: err, code: http.StatusServiceUnavailable}
+		return proxy.ServeSOCKS5(s.Context, listener, dialer.DialContext, s.Logger)
 	}
 
-	hijacker, ok := w.(http.Hijacker)
-	if !ok {
-		return &httpError{err: err, code: http.StatusInternalServerError}
-	}
+	fmt.Printf("export HTTPS_PROXY=http://%s\n", listener.Addr())
 
-	w.WriteHeader(http.StatusOK)
-	clientConn, _, err := hijacker.Hijack()
-	if err != nil {
-		return &httpError{err: err, code: http.StatusServiceUnavailable}
+	server := &http.Server{
+		Handler: proxy.NewHTTPHandler(dialer.DialContext, s.Logger),
 	}
 
-	go func() {
-		if err := iocopy(destConn, clientConn); err != nil {
-			s.Logger.Errorf("%v", err)
-		}
-	}()
-
-	go func() {
-		if err := iocopy(clientConn, destConn); err != nil {
-			s.Logger.Errorf("%v", err)
-		}
-	}()
-
-	return nil
-}
-
-func iocopy(dst io.WriteCloser, src io.ReadCloser) error {
-	defer dst.Close()
-	defer src.Close()
-
-	_, err := io.Copy(dst, src)
-	return err
+	return server.Serve(listener)
 }
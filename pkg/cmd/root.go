@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
@@ -50,6 +51,14 @@ func Execute() {
 	rootCmd := newRoot()
 
 	if err := rootCmd.Execute(); err != nil {
+		// the validation result is already printed
+		if errors.Cause(err) == errInvalidManifest {
+			os.Exit(1)
+		}
+		if exitErr, ok := errors.Cause(err).(*exitCodeError); ok {
+			os.Exit(exitErr.code)
+		}
+
 		debug, _ := rootCmd.PersistentFlags().GetBool(longFlagName(&globalOptions{}, "Debug"))
 
 		if debug {
@@ -77,23 +86,33 @@ func newRoot() *cobra.Command {
 
 	fs := rootCmd.PersistentFlags()
 
-	fs.StringVarP(&opts.ManifestFile,
-		longFlagName(opts, "ManifestFile"),
-		shortFlagName(opts, "ManifestFile"),
-		"./kubeone.yaml",
-		"Path to the KubeOne config")
+	fs.StringArrayVarP(&opts.ManifestFiles,
+		longFlagName(opts, "ManifestFiles"),
+		shortFlagName(opts, "ManifestFiles"),
+		[]string{"./kubeone.yaml"},
+		"Path to the KubeOne config. Can be repeated to merge the configs in the given order, each on top of the previous ones")
 
 	fs.StringVarP(&opts.TerraformState,
 		longFlagName(opts, "TerraformState"),
 		shortFlagName(opts, "TerraformState"),
 		"",
-		"Source for terraform output in JSON - to read from stdin. If path is a file, contents will be used. If path is a dictionary, `terraform output -json` is executed in this path")
+		"Source for terraform output in JSON, or terraform state (v4) - to read from stdin. If path is a file, contents will be used. If path is a directory, its local terraform.tfstate is read if it uses the local backend, otherwise `terraform output -json` is executed in this path")
 
 	fs.StringVarP(&opts.CredentialsFile,
 		longFlagName(opts, "CredentialsFile"),
 		shortFlagName(opts, "CredentialsFile"),
 		"",
-		"File to source credentials and secrets from")
+		"File to source credentials and secrets from, optionally encrypted by 'kubeone credentials encrypt'")
+
+	fs.StringVar(&opts.CredentialsExec,
+		longFlagName(opts, "CredentialsExec"),
+		"",
+		"Command printing the credentials as a JSON object, overriding the cloudProvider.credentialsExec plugin in the KubeOne config")
+
+	fs.StringVar(&opts.Inventory,
+		longFlagName(opts, "Inventory"),
+		"",
+		"Ansible inventory (INI, or YAML with the .yaml or .yml extension) to source the control plane hosts (kube_control_plane group) and the static worker hosts (kube_workers group) from")
 
 	fs.BoolVarP(&opts.Verbose,
 		longFlagName(opts, "Verbose"),
@@ -107,6 +126,11 @@ func newRoot() *cobra.Command {
 		false,
 		"debug output with stacktrace")
 
+	fs.BoolVar(&opts.AllowUnknownFields,
+		longFlagName(opts, "AllowUnknownFields"),
+		false,
+		"Ignore fields in the KubeOne config which are unknown to its API version, instead of failing")
+
 	rootCmd.AddCommand(
 		installCmd(fs),
 		applyCmd(fs),
@@ -114,9 +138,14 @@ func newRoot() *cobra.Command {
 		resetCmd(fs),
 		kubeconfigCmd(fs),
 		configCmd(fs),
+		credentialsCmd(),
 		versionCmd(),
 		statusCmd(fs),
 		proxyCmd(fs),
+		sshCmd(fs),
+		execCmd(fs),
+		supportBundleCmd(fs),
+		portForwardCmd(fs),
 		completionCmd(rootCmd),
 		documentCmd(rootCmd),
 	)
@@ -27,15 +27,24 @@ import (
 
 	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
 	"k8c.io/kubeone/pkg/apis/kubeone/config"
+	"k8c.io/kubeone/pkg/redact"
 	"k8c.io/kubeone/pkg/state"
 )
 
 type globalOptions struct {
-	ManifestFile    string `longflag:"manifest" shortflag:"m"`
+	ManifestFiles []string `longflag:"manifest" shortflag:"m"`
+	// ManifestFile is the last of the manifest files, the default relative
+	// paths are resolved against. Relative paths set in the manifests are
+	// resolved against the manifest declaring them.
+	ManifestFile    string
 	TerraformState  string `longflag:"tfjson" shortflag:"t"`
 	CredentialsFile string `longflag:"credentials" shortflag:"c"`
 	Verbose         bool   `longflag:"verbose" shortflag:"v"`
 	Debug           bool   `longflag:"debug" shortflag:"d"`
+
+	AllowUnknownFields bool   `longflag:"allow-unknown-fields"`
+	CredentialsExec    string `longflag:"credentials-exec"`
+	Inventory          string `longflag:"inventory"`
 }
 
 func (opts *globalOptions) BuildState() (*state.State, error) {
@@ -44,13 +53,24 @@ func (opts *globalOptions) BuildState() (*state.State, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to initialize State")
 	}
-	s.Logger = newLogger(opts.Verbose)
-
-	cluster, err := loadClusterConfig(opts.ManifestFile, opts.TerraformState, opts.CredentialsFile, s.Logger)
+	logger := newLogger(opts.Verbose)
+	s.Logger = logger
+
+	var secrets []string
+	cluster, err := loadClusterConfig(opts.ManifestFiles, opts.TerraformState, opts.CredentialsFile, s.Logger,
+		config.AllowUnknownFields(opts.AllowUnknownFields),
+		config.CollectSecrets(&secrets),
+		config.Inventory(opts.Inventory),
+		config.CredentialsExec(opts.CredentialsExec))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to load cluster")
 	}
 
+	if len(secrets) > 0 {
+		logger.AddHook(newRedactHook(secrets))
+	}
+	s.Secrets = secrets
+
 	s.Cluster = cluster
 	s.ManifestFilePath = opts.ManifestFile
 	s.CredentialsFilePath = opts.CredentialsFile
@@ -78,11 +98,14 @@ func shortFlagName(obj interface{}, fieldName string) string {
 func persistentGlobalOptions(fs *pflag.FlagSet) (*globalOptions, error) {
 	gf := &globalOptions{}
 
-	manifestFile, err := fs.GetString(longFlagName(gf, "ManifestFile"))
+	manifestFiles, err := fs.GetStringArray(longFlagName(gf, "ManifestFiles"))
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	gf.ManifestFile = manifestFile
+	gf.ManifestFiles = manifestFiles
+	if len(manifestFiles) > 0 {
+		gf.ManifestFile = manifestFiles[len(manifestFiles)-1]
+	}
 
 	verbose, err := fs.GetBool(longFlagName(gf, "Verbose"))
 	if err != nil {
@@ -102,6 +125,24 @@ func persistentGlobalOptions(fs *pflag.FlagSet) (*globalOptions, error) {
 	}
 	gf.CredentialsFile = creds
 
+	allowUnknownFields, err := fs.GetBool(longFlagName(gf, "AllowUnknownFields"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gf.AllowUnknownFields = allowUnknownFields
+
+	credentialsExec, err := fs.GetString(longFlagName(gf, "CredentialsExec"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gf.CredentialsExec = credentialsExec
+
+	inventory, err := fs.GetString(longFlagName(gf, "Inventory"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gf.Inventory = inventory
+
 	return gf, nil
 }
 
@@ -119,8 +160,35 @@ func newLogger(verbose bool) *logrus.Logger {
 	return logger
 }
 
-func loadClusterConfig(filename, terraformOutputPath, credentialsFilePath string, logger logrus.FieldLogger) (*kubeoneapi.KubeOneCluster, error) {
-	a, err := config.LoadKubeOneCluster(filename, terraformOutputPath, credentialsFilePath, logger)
+// redactHook replaces the secrets in the log entries with REDACTED
+type redactHook struct {
+	replacer *redact.Replacer
+}
+
+func newRedactHook(secrets []string) *redactHook {
+	return &redactHook{replacer: redact.NewReplacer(secrets)}
+}
+
+func (h *redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.replacer.Replace(entry.Message)
+	for key, value := range entry.Data {
+		switch value := value.(type) {
+		case string:
+			entry.Data[key] = h.replacer.Replace(value)
+		case error:
+			entry.Data[key] = h.replacer.Replace(value.Error())
+		}
+	}
+
+	return nil
+}
+
+func loadClusterConfig(filenames []string, terraformOutputPath, credentialsFilePath string, logger logrus.FieldLogger, opts ...config.LoadOption) (*kubeoneapi.KubeOneCluster, error) {
+	a, err := config.LoadKubeOneCluster(filenames, terraformOutputPath, credentialsFilePath, logger, opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to load a given KubeOneCluster object")
 	}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh"
+)
+
+// sshCmd returns the structure for declaring the "ssh" subcommand.
+func sshCmd(rootFlags *pflag.FlagSet) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ssh <hostname|address|index>",
+		Short: "Open an interactive shell on the cluster host",
+		Long: heredoc.Doc(`
+			Open an interactive shell on the cluster host.
+
+			The host is selected by its hostname, public or private address, or its index, where control-plane hosts come
+			first, followed by the static worker hosts, in the order as defined in the manifest. The connection is
+			established using the SSH settings from the manifest, including the bastion host.
+		`),
+		Example: heredoc.Doc(`
+			kubeone ssh -m mycluster.yaml -t terraformoutput.json 0
+			kubeone ssh -m mycluster.yaml -t terraformoutput.json 10.0.0.12
+		`),
+		Args: cobra.ExactArgs(1),
+		// the exit code of the shell is propagated by Execute
+		SilenceErrors: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			gopts, err := persistentGlobalOptions(rootFlags)
+			if err != nil {
+				return errors.Wrap(err, "unable to get global flags")
+			}
+
+			return runSSH(gopts, args[0])
+		},
+	}
+
+	return cmd
+}
+
+// exitCodeError is returned by the ssh command when the shell exits with the
+// non-zero exit code, which is propagated by Execute, same as ssh does
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func runSSH(opts *globalOptions, hostRef string) error {
+	s, err := opts.BuildState()
+	if err != nil {
+		return err
+	}
+
+	host, err := findHost(s.Cluster, hostRef)
+	if err != nil {
+		return err
+	}
+
+	conn, err := s.Connector.Connect(host)
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to %s", host.PublicAddress)
+	}
+
+	shell, ok := conn.(ssh.Shell)
+	if !ok {
+		conn.Close()
+		return errors.New("connection does not support interactive shell")
+	}
+
+	exitCode, err := shell.Shell(os.Stdin, os.Stdout, os.Stderr)
+	conn.Close()
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return &exitCodeError{code: exitCode}
+	}
+
+	return nil
+}
+
+// findHost finds the host by its hostname, public or private address, or
+// its index among all hosts. The hostname and addresses take precedence over
+// the index, and must match a single host.
+func findHost(cluster *kubeoneapi.KubeOneCluster, hostRef string) (kubeoneapi.HostConfig, error) {
+	hosts := allHosts(cluster)
+
+	matches := []kubeoneapi.HostConfig{}
+	for _, host := range hosts {
+		if hostRef == host.Hostname || hostRef == host.PublicAddress || hostRef == host.PrivateAddress {
+			matches = append(matches, host)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+	case 1:
+		return matches[0], nil
+	default:
+		indexes := []string{}
+		for _, host := range matches {
+			indexes = append(indexes, strconv.Itoa(host.ID))
+		}
+
+		return kubeoneapi.HostConfig{}, errors.Errorf("host %q is ambiguous, it matches the hosts %s, select the host by its index instead", hostRef, strings.Join(indexes, ", "))
+	}
+
+	if idx, err := strconv.Atoi(hostRef); err == nil {
+		for _, host := range hosts {
+			if host.ID == idx {
+				return host, nil
+			}
+		}
+	}
+
+	return kubeoneapi.HostConfig{}, errors.Errorf("host %q not found in the manifest", hostRef)
+}
+
+// allHosts returns the control-plane hosts followed by the static worker hosts
+func allHosts(cluster *kubeoneapi.KubeOneCluster) []kubeoneapi.HostConfig {
+	return append(append([]kubeoneapi.HostConfig{}, cluster.ControlPlane.Hosts...), cluster.StaticWorkers.Hosts...)
+}
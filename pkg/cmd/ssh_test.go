@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+// testHostsCluster has the control-plane hosts 0 and 1, and the static worker
+// host 2, named "1" and sharing the private address with the host 1
+var testHostsCluster = &kubeoneapi.KubeOneCluster{
+	ControlPlane: kubeoneapi.ControlPlaneConfig{
+		Hosts: []kubeoneapi.HostConfig{
+			{ID: 0, Hostname: "cp-0", PublicAddress: "192.0.2.1", PrivateAddress: "10.0.0.1"},
+			{ID: 1, Hostname: "cp-1", PublicAddress: "192.0.2.2", PrivateAddress: "10.0.0.2"},
+		},
+	},
+	StaticWorkers: kubeoneapi.StaticWorkersConfig{
+		Hosts: []kubeoneapi.HostConfig{
+			{ID: 2, Hostname: "1", PublicAddress: "192.0.2.3", PrivateAddress: "10.0.0.2"},
+		},
+	},
+}
+
+func TestFindHost(t *testing.T) {
+	tests := []struct {
+		name        string
+		hostRef     string
+		expectedID  int
+		expectedErr string
+	}{
+		{
+			name:       "hostname",
+			hostRef:    "cp-1",
+			expectedID: 1,
+		},
+		{
+			name:       "public address",
+			hostRef:    "192.0.2.3",
+			expectedID: 2,
+		},
+		{
+			name:       "private address",
+			hostRef:    "10.0.0.1",
+			expectedID: 0,
+		},
+		{
+			name:       "index",
+			hostRef:    "0",
+			expectedID: 0,
+		},
+		{
+			name:       "hostname takes precedence over the index",
+			hostRef:    "1",
+			expectedID: 2,
+		},
+		{
+			name:        "ambiguous address",
+			hostRef:     "10.0.0.2",
+			expectedErr: `host "10.0.0.2" is ambiguous, it matches the hosts 1, 2`,
+		},
+		{
+			name:        "index out of range",
+			hostRef:     "3",
+			expectedErr: `host "3" not found in the manifest`,
+		},
+		{
+			name:        "negative index",
+			hostRef:     "-1",
+			expectedErr: `host "-1" not found in the manifest`,
+		},
+		{
+			name:        "unknown host",
+			hostRef:     "worker-9",
+			expectedErr: `host "worker-9" not found in the manifest`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, err := findHost(testHostsCluster, tt.hostRef)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error %q, but got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if host.ID != tt.expectedID {
+				t.Errorf("expected host %d, but got %d", tt.expectedID, host.ID)
+			}
+		})
+	}
+}
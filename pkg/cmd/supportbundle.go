@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8c.io/kubeone/pkg/supportbundle"
+)
+
+type supportBundleOpts struct {
+	globalOptions
+	OutputFile string `longflag:"output" shortflag:"o"`
+}
+
+// supportBundleCmd returns the structure for declaring the "support-bundle" subcommand.
+func supportBundleCmd(rootFlags *pflag.FlagSet) *cobra.Command {
+	opts := &supportBundleOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Collect diagnostics of the cluster into an archive",
+		Long: heredoc.Doc(`
+			Collect diagnostics of the cluster into a tar.gz archive.
+
+			The following diagnostics are collected from each host over SSH: kubelet, containerd and docker journals, logs
+			of the static pods, files in /etc/kubernetes (except the PKI), /etc/os-release and the kubeadm configs rendered
+			by KubeOne. The nodes, pods, etcd members and host probe results are collected from the cluster.
+
+			Secrets, such as bootstrap tokens, private keys, passwords and the cloud provider credentials, are scrubbed from
+			the collected diagnostics. Diagnostics which can't be collected are listed in errors.txt in the archive.
+		`),
+		Example: `kubeone support-bundle -m mycluster.yaml -t terraformoutput.json -o support-bundle.tar.gz`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			gopts, err := persistentGlobalOptions(rootFlags)
+			if err != nil {
+				return errors.Wrap(err, "unable to get global flags")
+			}
+			opts.globalOptions = *gopts
+
+			return runSupportBundle(opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(
+		&opts.OutputFile,
+		longFlagName(opts, "OutputFile"),
+		shortFlagName(opts, "OutputFile"),
+		"",
+		"path to the support bundle archive (default kubeone-support-bundle-<timestamp>.tar.gz)")
+
+	return cmd
+}
+
+func runSupportBundle(opts *supportBundleOpts) error {
+	s, err := opts.BuildState()
+	if err != nil {
+		return err
+	}
+
+	if opts.OutputFile == "" {
+		opts.OutputFile = fmt.Sprintf("kubeone-support-bundle-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	if err = supportbundle.Collect(s, opts.OutputFile); err != nil {
+		return err
+	}
+
+	s.Logger.Infof("Support bundle saved to %s", opts.OutputFile)
+
+	return nil
+}
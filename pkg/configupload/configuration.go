@@ -17,13 +17,17 @@ limitations under the License.
 package configupload
 
 import (
+	"archive/tar"
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -31,21 +35,49 @@ import (
 	"k8c.io/kubeone/pkg/ssh"
 )
 
+const (
+	// defaultFileMode is the mode of uploaded files
+	defaultFileMode os.FileMode = 0644
+	// privateFileMode is the mode of uploaded private keys and credentials
+	privateFileMode os.FileMode = 0600
+
+	// manifestFilename is the name of the manifest of uploaded files, kept
+	// in the target directory
+	manifestFilename = ".upload-manifest"
+)
+
 // Configuration holds a map of generated files
 type Configuration struct {
 	files map[string]string
+	modes map[string]os.FileMode
 }
 
 // NewConfiguration constructor
 func NewConfiguration() *Configuration {
 	return &Configuration{
 		files: make(map[string]string),
+		modes: make(map[string]os.FileMode),
 	}
 }
 
-// AddFile save file contents for future references
+// AddFile save file contents for future references. Private keys (*.key
+// files) are uploaded with the 0600 mode, all other files with the 0644 mode.
 func (c *Configuration) AddFile(filename string, content string) {
+	c.AddFileWithMode(filename, content, fileMode(filename))
+}
+
+// AddFileWithMode save file contents, to be uploaded with the given mode
+func (c *Configuration) AddFileWithMode(filename string, content string, mode os.FileMode) {
 	c.files[filename] = strings.TrimSpace(content) + "\n"
+	c.modes[filename] = mode
+}
+
+func fileMode(filename string) os.FileMode {
+	if strings.HasSuffix(filename, ".key") {
+		return privateFileMode
+	}
+
+	return defaultFileMode
 }
 
 // AddFilePath saves file contents from a file on filesystem for future references
@@ -70,42 +102,124 @@ func (c *Configuration) AddFilePath(filename, filePath, manifestFilePath string)
 }
 
 // UploadTo directory all the files
+//
+// Files are streamed as a single tar archive. Content hashes and modes of the
+// uploaded files are recorded in the manifest kept in the directory, and files
+// not changed since the last upload are skipped.
 func (c *Configuration) UploadTo(conn ssh.Connection, directory string) error {
-	for filename, content := range c.files {
-		target := filepath.Join(directory, filename)
+	uploaded, err := readManifest(conn, directory)
+	if err != nil {
+		return err
+	}
 
-		// ensure the base dir exists
-		dir := filepath.Dir(target)
-		_, _, _, err := conn.Exec(fmt.Sprintf(`mkdir -p -- "%s"`, dir))
-		if err != nil {
-			return errors.Wrapf(err, "failed to create ./%s directory", dir)
+	changed := []string{}
+	for filename := range c.files {
+		if uploaded[filename] != c.manifestEntry(filename) {
+			changed = append(changed, filename)
 		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Strings(changed)
 
-		w, err := conn.File(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
-		if err != nil {
-			return errors.Wrapf(err, "failed to open remote file for write: %s", filename)
-		}
-		defer w.Close()
+	// files uploaded by others are kept in the manifest
+	manifest := uploaded
+	for filename := range c.files {
+		manifest[filename] = c.manifestEntry(filename)
+	}
 
-		_, err = io.Copy(w, strings.NewReader(content))
-		if err != nil {
-			return errors.Wrapf(err, "failed to write remote file %s", filename)
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, filename := range changed {
+		if err = addToTar(tw, filename, c.modes[filename], []byte(c.files[filename])); err != nil {
+			return err
 		}
+	}
+	// the manifest goes last, so it's not updated if extracting fails
+	if err = addToTar(tw, manifestFilename, defaultFileMode, formatManifest(manifest)); err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to create tar archive")
+	}
 
-		if wchmod, ok := w.(interface{ Chmod(os.FileMode) error }); ok {
-			if err := wchmod.Chmod(0644); err != nil {
-				return errors.Wrapf(err, "failed to chmod %s", filename)
-			}
-		}
+	var stderr strings.Builder
+	cmd := fmt.Sprintf(`mkdir -p -- "%s" && tar -x -m -p --no-same-owner -C "%s" -f -`, directory, directory)
+	if _, err = conn.POpen(cmd, &buf, ioutil.Discard, &stderr); err != nil {
+		return errors.Wrapf(err, "failed to upload files to %s: %s", directory, strings.TrimSpace(stderr.String()))
 	}
 
 	return nil
 }
 
+func (c *Configuration) manifestEntry(filename string) string {
+	return fmt.Sprintf("%x %o", sha256.Sum256([]byte(c.files[filename])), c.modes[filename])
+}
+
+func addToTar(tw *tar.Writer, filename string, mode os.FileMode, content []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     filename,
+		Mode:     int64(mode),
+		Size:     int64(len(content)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %s to tar archive", filename)
+	}
+
+	_, err = tw.Write(content)
+
+	return errors.Wrapf(err, "failed to add %s to tar archive", filename)
+}
+
+// readManifest reads the manifest of files uploaded to the directory,
+// returning the hash and the mode by filename. Files removed from the
+// directory since the upload are left out.
+func readManifest(conn ssh.Connection, directory string) (map[string]string, error) {
+	cmd := fmt.Sprintf(`cd -- "%s" 2>/dev/null && [ -f %s ] || exit 0
+while read -r sum mode name; do
+	[ -f "$name" ] && echo "$sum $mode $name"
+done < %s
+true`, directory, manifestFilename, manifestFilename)
+
+	stdout, stderr, _, err := ssh.ExecIdempotent(conn, cmd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read manifest of uploaded files: %s", stderr)
+	}
+
+	manifest := map[string]string{}
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		manifest[fields[2]] = fields[0] + " " + fields[1]
+	}
+
+	return manifest, nil
+}
+
+func formatManifest(manifest map[string]string) []byte {
+	filenames := []string{}
+	for filename := range manifest {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var buf bytes.Buffer
+	for _, filename := range filenames {
+		fmt.Fprintf(&buf, "%s %s\n", manifest[filename], filename)
+	}
+
+	return buf.Bytes()
+}
+
 // Download a files matching `source` pattern
 func (c *Configuration) Download(conn ssh.Connection, source string, prefix string) error {
 	// list files
-	stdout, stderr, _, err := conn.Exec(fmt.Sprintf(`cd -- "%s" && find * -type f`, source))
+	stdout, stderr, _, err := ssh.ExecIdempotent(conn, fmt.Sprintf(`cd -- "%s" && find * -type f`, source))
 	if err != nil {
 		return errors.Wrapf(err, "%s", stderr)
 	}
@@ -120,17 +234,25 @@ func (c *Configuration) Download(conn ssh.Connection, source string, prefix stri
 		}
 
 		var buf bytes.Buffer
-		r, err := conn.File(fullsource, os.O_RDONLY)
-		if err != nil {
-			return errors.Wrapf(err, "failed to open remote file for read: %s", fullsource)
-		}
+		err = ssh.RetryOnLost(conn, func() error {
+			buf.Reset()
+
+			r, err := conn.File(fullsource, os.O_RDONLY)
+			if err != nil {
+				return errors.Wrapf(err, "failed to open remote file for read: %s", fullsource)
+			}
+			defer r.Close()
+
+			_, err = io.Copy(&buf, r)
 
-		_, err = io.Copy(&buf, r)
-		if err != nil {
 			return errors.Wrapf(err, "failed to read remote file: %s", fullsource)
+		})
+		if err != nil {
+			return err
 		}
 
 		c.files[localfile] = buf.String()
+		c.modes[localfile] = fileMode(localfile)
 	}
 
 	return nil
@@ -143,17 +265,13 @@ func (c *Configuration) Debug() {
 	}
 }
 
-// Backup dumps the files into a .tar.gz archive.
-func (c *Configuration) Backup(target string) error {
-	archive, err := archive.NewTarGzip(target)
-	if err != nil {
-		return errors.Wrap(err, "failed to open archive")
-	}
+// Backup dumps the files into a .tar.gz archive written to the writer.
+func (c *Configuration) Backup(w io.Writer) error {
+	archive := archive.NewTarGzipWriter(w)
 	defer archive.Close()
 
 	for filename, content := range c.files {
-		err = archive.Add(filename, content)
-		if err != nil {
+		if err := archive.Add(filename, content); err != nil {
 			return errors.Wrapf(err, "failed to add %s to archive", filename)
 		}
 	}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configupload
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8c.io/kubeone/pkg/ssh"
+)
+
+func TestUploadTo(t *testing.T) {
+	conn, err := ssh.NewLocalConnection(ssh.NewConnector(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	dir := t.TempDir()
+	readFile := func(filename string) (string, os.FileMode) {
+		t.Helper()
+
+		path := filepath.Join(dir, filename)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(content), fi.Mode().Perm()
+	}
+
+	c := NewConfiguration()
+	c.AddFile("cfg/config.yaml", "config")
+	c.AddFile("pki/ca.key", "key")
+
+	if err = c.UploadTo(conn, dir); err != nil {
+		t.Fatal(err)
+	}
+	if content, mode := readFile("cfg/config.yaml"); content != "config\n" || mode != 0644 {
+		t.Errorf("expected cfg/config.yaml with mode 0644, but got %q with mode %o", content, mode)
+	}
+	if content, mode := readFile("pki/ca.key"); content != "key\n" || mode != 0600 {
+		t.Errorf("expected pki/ca.key with mode 0600, but got %q with mode %o", content, mode)
+	}
+
+	// unchanged files are not uploaded again
+	if err = ioutil.WriteFile(filepath.Join(dir, "cfg/config.yaml"), []byte("modified on host"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c.AddFile("pki/ca.key", "new key")
+
+	if err = c.UploadTo(conn, dir); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := readFile("cfg/config.yaml"); content != "modified on host" {
+		t.Errorf("expected unchanged cfg/config.yaml to be skipped, but it was uploaded again")
+	}
+	if content, _ := readFile("pki/ca.key"); content != "new key\n" {
+		t.Errorf("expected changed pki/ca.key to be uploaded, but got %q", content)
+	}
+
+	// files removed from the host are uploaded again
+	if err = os.Remove(filepath.Join(dir, "cfg/config.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.UploadTo(conn, dir); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := readFile("cfg/config.yaml"); content != "config\n" {
+		t.Errorf("expected removed cfg/config.yaml to be uploaded again, but got %q", content)
+	}
+}
@@ -18,7 +18,6 @@ package credentials
 
 import (
 	"encoding/base64"
-	"io/ioutil"
 	"os"
 	"strings"
 
@@ -101,8 +100,9 @@ type ProviderEnvironmentVariable struct {
 	MachineControllerName string
 }
 
-func Any(credentialsFilePath string) (map[string]string, error) {
-	credentialsFinder, err := newCredsFinder(credentialsFilePath)
+// Any returns all credentials found in the sources
+func Any(cloudProvider kubeone.CloudProviderSpec, credentialsFilePath string) (map[string]string, error) {
+	credentialsFinder, err := newCredsFinder(cloudProvider.CredentialsExec, credentialsFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +120,7 @@ func Any(credentialsFilePath string) (map[string]string, error) {
 
 // ProviderCredentials implements fetching credentials for each supported provider
 func ProviderCredentials(cloudProvider kubeone.CloudProviderSpec, credentialsFilePath string) (map[string]string, error) {
-	credentialsFinder, err := newCredsFinder(credentialsFilePath)
+	credentialsFinder, err := newCredsFinder(cloudProvider.CredentialsExec, credentialsFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +188,10 @@ func ProviderCredentials(cloudProvider kubeone.CloudProviderSpec, credentialsFil
 	return nil, errors.New("no provider matched")
 }
 
-func newCredsFinder(credentialsFilePath string) (lookupFunc, error) {
+// newCredsFinder returns the function looking up the credentials in the
+// environment variables, followed by the credentials plugin and the
+// credentials file
+func newCredsFinder(credentialsExec *kubeone.CredentialsExecConfig, credentialsFilePath string) (lookupFunc, error) {
 	staticMap := map[string]string{}
 	finder := func(name string) string {
 		if val := os.Getenv(name); val != "" {
@@ -197,17 +200,25 @@ func newCredsFinder(credentialsFilePath string) (lookupFunc, error) {
 		return staticMap[name]
 	}
 
-	if credentialsFilePath == "" {
-		return finder, nil
-	}
+	if credentialsFilePath != "" {
+		buf, err := ReadFile(credentialsFilePath)
+		if err != nil {
+			return nil, err
+		}
 
-	buf, err := ioutil.ReadFile(credentialsFilePath)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to load credentials file")
+		if err = yaml.Unmarshal(buf, &staticMap); err != nil {
+			return nil, errors.Wrap(err, "unable to unmarshal credentials file")
+		}
 	}
 
-	if err = yaml.Unmarshal(buf, &staticMap); err != nil {
-		return nil, errors.Wrap(err, "unable to unmarshal credentials file")
+	if credentialsExec != nil {
+		execCreds, err := execCredentials(credentialsExec)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range execCreds {
+			staticMap[k] = v
+		}
 	}
 
 	return finder, nil
@@ -0,0 +1,272 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	// PassphraseEnvVar is the name of the environment variable used to
+	// source the passphrase for the encrypted credentials file
+	PassphraseEnvVar = "KUBEONE_CREDENTIALS_PASSPHRASE" //nolint:gosec
+	// PassphraseFileEnvVar is the name of the environment variable used to
+	// source the path of the key file, holding the passphrase for the
+	// encrypted credentials file
+	PassphraseFileEnvVar = "KUBEONE_CREDENTIALS_PASSPHRASE_FILE" //nolint:gosec
+
+	encryptedBlockType = "KUBEONE ENCRYPTED CREDENTIALS"
+	versionHeader      = "Version"
+
+	// encryptionVersion1 derives the AES-256-GCM key from the passphrase
+	// using scrypt
+	encryptionVersion1 = "1"
+	scryptN            = 1 << 15
+	scryptR            = 8
+	scryptP            = 1
+	keyLength          = 32
+	saltLength         = 16
+)
+
+var (
+	// decryptedCache holds the decrypted credentials files, keyed by the
+	// hash of the encrypted content, so the passphrase is asked for only once
+	decryptedCache   = map[[sha256.Size]byte][]byte{}
+	decryptedCacheMu sync.Mutex
+	// decryptedPassphrase is the passphrase the credentials file was
+	// decrypted with, used to encrypt the PKI backup
+	decryptedPassphrase []byte
+)
+
+// IsEncrypted returns whether the credentials file is encrypted
+func IsEncrypted(content []byte) bool {
+	block, _ := pem.Decode(content)
+	return block != nil && block.Type == encryptedBlockType
+}
+
+// Encrypt encrypts the credentials file with the key derived from the passphrase.
+// The encrypted file is PEM encoded, so it can be committed to git.
+func Encrypt(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase must not be empty")
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, errors.Wrap(err, "failed to generate salt")
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	data := append(salt, nonce...)
+	data = aead.Seal(data, nonce, plaintext, additionalData(encryptionVersion1))
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    encryptedBlockType,
+		Headers: map[string]string{versionHeader: encryptionVersion1},
+		Bytes:   data,
+	}), nil
+}
+
+// Decrypt decrypts the credentials file encrypted by Encrypt
+func Decrypt(content, passphrase []byte) ([]byte, error) {
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != encryptedBlockType {
+		return nil, errors.New("credentials file is not encrypted")
+	}
+
+	version := block.Headers[versionHeader]
+	if version != encryptionVersion1 {
+		return nil, errors.Errorf("unsupported credentials file encryption version %q", version)
+	}
+
+	if len(block.Bytes) < saltLength {
+		return nil, errors.New("encrypted credentials file is truncated")
+	}
+
+	aead, err := newAEAD(passphrase, block.Bytes[:saltLength])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(block.Bytes) < saltLength+aead.NonceSize() {
+		return nil, errors.New("encrypted credentials file is truncated")
+	}
+	nonce := block.Bytes[saltLength : saltLength+aead.NonceSize()]
+	ciphertext := block.Bytes[saltLength+aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(version))
+	if err != nil {
+		return nil, errors.New("failed to decrypt the credentials file, the passphrase is wrong or the file is corrupted")
+	}
+
+	return plaintext, nil
+}
+
+// ReadFile reads the credentials file, decrypting it if it's encrypted.
+// The passphrase is sourced by ReadPassphrase.
+func ReadFile(credentialsFilePath string) ([]byte, error) {
+	content, err := ioutil.ReadFile(credentialsFilePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load credentials file")
+	}
+
+	if !IsEncrypted(content) {
+		return content, nil
+	}
+
+	decryptedCacheMu.Lock()
+	defer decryptedCacheMu.Unlock()
+
+	cacheKey := sha256.Sum256(content)
+	if plaintext, ok := decryptedCache[cacheKey]; ok {
+		return plaintext, nil
+	}
+
+	passphrase, err := ReadPassphrase("", fmt.Sprintf("Enter passphrase for the credentials file %q: ", credentialsFilePath), false)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := Decrypt(content, passphrase)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to decrypt credentials file %q", credentialsFilePath)
+	}
+
+	decryptedCache[cacheKey] = plaintext
+	decryptedPassphrase = passphrase
+
+	return plaintext, nil
+}
+
+// ConfiguredPassphrase returns the passphrase set in the environment, or the
+// passphrase the credentials file was decrypted with, without asking for it.
+// It returns nil if no passphrase is configured.
+func ConfiguredPassphrase() ([]byte, error) {
+	passphrase, ok, err := environmentPassphrase("")
+	if err != nil || ok {
+		return passphrase, err
+	}
+
+	decryptedCacheMu.Lock()
+	defer decryptedCacheMu.Unlock()
+
+	return decryptedPassphrase, nil
+}
+
+// ReadPassphrase sources the passphrase from the given passphrase file, the
+// passphrase file or the passphrase set in the environment, or asks for it
+// if running in the terminal. The passphrase is asked for twice if confirm is set.
+func ReadPassphrase(passphraseFile, prompt string, confirm bool) ([]byte, error) {
+	if passphrase, ok, err := environmentPassphrase(passphraseFile); err != nil || ok {
+		return passphrase, err
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.Errorf("no credentials passphrase is provided (use the %s or %s environment variables)", PassphraseFileEnvVar, PassphraseEnvVar)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read credentials passphrase")
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Confirm passphrase: ")
+		confirmed, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read credentials passphrase")
+		}
+		if !bytes.Equal(passphrase, confirmed) {
+			return nil, errors.New("passphrases don't match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// environmentPassphrase sources the passphrase from the given passphrase file,
+// the passphrase file or the passphrase set in the environment. It returns
+// whether the passphrase is configured.
+func environmentPassphrase(passphraseFile string) ([]byte, bool, error) {
+	if passphraseFile == "" {
+		passphraseFile = os.Getenv(PassphraseFileEnvVar)
+	}
+	if passphraseFile != "" {
+		passphrase, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to read credentials passphrase file %q", passphraseFile)
+		}
+
+		return bytes.TrimRight(passphrase, "\r\n"), true, nil
+	}
+
+	if passphrase := os.Getenv(PassphraseEnvVar); len(passphrase) > 0 {
+		return []byte(passphrase), true, nil
+	}
+
+	return nil, false, nil
+}
+
+func newAEAD(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keyLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive the encryption key")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize the cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to initialize the cipher")
+	}
+
+	return aead, nil
+}
+
+// additionalData authenticates the encryption version along the ciphertext
+func additionalData(version string) []byte {
+	return []byte(encryptedBlockType + " " + versionHeader + " " + version)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	plaintext := []byte("HCLOUD_TOKEN: hunter2\n")
+
+	encrypted, err := Encrypt(plaintext, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Fatalf("expected the encrypted file to be recognized:\n%s", encrypted)
+	}
+	if bytes.Contains(encrypted, []byte("hunter2")) {
+		t.Fatalf("expected the credentials to be encrypted:\n%s", encrypted)
+	}
+
+	tests := []struct {
+		name          string
+		content       []byte
+		passphrase    string
+		expectedError bool
+	}{
+		{
+			name:       "correct passphrase",
+			content:    encrypted,
+			passphrase: "passphrase",
+		},
+		{
+			name:          "wrong passphrase",
+			content:       encrypted,
+			passphrase:    "wrong",
+			expectedError: true,
+		},
+		{
+			name:          "tampered file",
+			content:       bytes.Replace(encrypted, []byte("Version: 1"), []byte("Version: 2"), 1),
+			passphrase:    "passphrase",
+			expectedError: true,
+		},
+		{
+			name:          "plaintext file",
+			content:       plaintext,
+			passphrase:    "passphrase",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decrypted, err := Decrypt(tc.content, []byte(tc.passphrase))
+			if tc.expectedError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("expected %q, got %q", plaintext, decrypted)
+			}
+		})
+	}
+}
+
+func TestProviderCredentialsEncryptedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	encrypted, err := Encrypt([]byte("HCLOUD_TOKEN: hunter2\n"), []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentialsFilePath := filepath.Join(dir, "credentials.enc.yaml")
+	passphraseFilePath := filepath.Join(dir, "passphrase")
+	for path, content := range map[string][]byte{credentialsFilePath: encrypted, passphraseFilePath: []byte("passphrase\n")} {
+		if err = ioutil.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Setenv(PassphraseFileEnvVar, passphraseFilePath)
+	defer os.Unsetenv(PassphraseFileEnvVar)
+
+	creds, err := ProviderCredentials(kubeone.CloudProviderSpec{Hetzner: &kubeone.HetznerSpec{}}, credentialsFilePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds[HetznerTokenKeyMC] != "hunter2" {
+		t.Errorf("expected the token to be decrypted, got %v", creds)
+	}
+}
+
+func TestConfiguredPassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	encrypted, err := Encrypt([]byte("HCLOUD_TOKEN: hunter2\n"), []byte("file passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentialsFilePath := filepath.Join(dir, "credentials.enc.yaml")
+	if err = ioutil.WriteFile(credentialsFilePath, encrypted, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	decryptedCacheMu.Lock()
+	decryptedPassphrase = nil
+	decryptedCacheMu.Unlock()
+
+	passphrase, err := ConfiguredPassphrase()
+	if err != nil || passphrase != nil {
+		t.Fatalf("expected no passphrase, got %q (%v)", passphrase, err)
+	}
+
+	// the passphrase used to decrypt the credentials file is remembered
+	os.Setenv(PassphraseEnvVar, "file passphrase")
+	if _, err = ReadFile(credentialsFilePath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.Unsetenv(PassphraseEnvVar)
+
+	passphrase, err = ConfiguredPassphrase()
+	if err != nil || string(passphrase) != "file passphrase" {
+		t.Fatalf("expected the credentials file passphrase, got %q (%v)", passphrase, err)
+	}
+
+	// the passphrase set in the environment takes precedence
+	os.Setenv(PassphraseEnvVar, "environment passphrase")
+	defer os.Unsetenv(PassphraseEnvVar)
+
+	passphrase, err = ConfiguredPassphrase()
+	if err != nil || string(passphrase) != "environment passphrase" {
+		t.Fatalf("expected the environment passphrase, got %q (%v)", passphrase, err)
+	}
+}
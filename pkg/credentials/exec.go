@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+var (
+	// execCache holds the credentials printed by the plugins, keyed by the
+	// command line, so every plugin runs only once per KubeOne run
+	execCache   = map[string]map[string]string{}
+	execCacheMu sync.Mutex
+)
+
+// execCredentials runs the credentials plugin, and returns the credentials
+// it printed to the standard output. The plugin inherits the standard input
+// and error, so it can ask for the login to the secrets store.
+func execCredentials(cfg *kubeone.CredentialsExecConfig) (map[string]string, error) {
+	execCacheMu.Lock()
+	defer execCacheMu.Unlock()
+
+	key := strings.Join(append([]string{cfg.Command}, cfg.Args...), "\x00")
+	if creds, ok := execCache[key]; ok {
+		return creds, nil
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "failed to run the credentials plugin %q", cfg.Command)
+	}
+
+	creds := map[string]string{}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return nil, errors.Wrapf(err, "unable to unmarshal the output of the credentials plugin %q", cfg.Command)
+	}
+
+	known := map[string]bool{}
+	for _, k := range allKeys {
+		known[k] = true
+	}
+
+	unknown := []string{}
+	for k := range creds {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, errors.Errorf("credentials plugin %q printed unknown keys: %s", cfg.Command, strings.Join(unknown, ", "))
+	}
+
+	execCache[key] = creds
+
+	return creds, nil
+}
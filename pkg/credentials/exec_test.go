@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+func TestProviderCredentialsExec(t *testing.T) {
+	tests := []struct {
+		name            string
+		output          string
+		credentialsFile string
+		env             map[string]string
+		expected        map[string]string
+		expectedError   bool
+	}{
+		{
+			name:     "credentials printed by the plugin",
+			output:   `{"HCLOUD_TOKEN": "plugin-token"}`,
+			expected: map[string]string{HetznerTokenKeyMC: "plugin-token"},
+		},
+		{
+			name:            "plugin takes precedence over the credentials file",
+			output:          `{"HCLOUD_TOKEN": "plugin-token"}`,
+			credentialsFile: "HCLOUD_TOKEN: file-token\n",
+			expected:        map[string]string{HetznerTokenKeyMC: "plugin-token"},
+		},
+		{
+			name:     "environment takes precedence over the plugin",
+			output:   `{"HCLOUD_TOKEN": "plugin-token"}`,
+			env:      map[string]string{HetznerTokenKey: "env-token"},
+			expected: map[string]string{HetznerTokenKeyMC: "env-token"},
+		},
+		{
+			name:          "credentials are validated",
+			output:        `{"DIGITALOCEAN_TOKEN": "plugin-token"}`,
+			expectedError: true,
+		},
+		{
+			name:          "unknown keys",
+			output:        `{"HCLOUD_TOKEN": "plugin-token", "HCLOUD_TOKEN_TYPO": "plugin-token"}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid output",
+			output:        `HCLOUD_TOKEN=plugin-token`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kubeone-credentials")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			// the plugin records its runs to verify the results are cached
+			runsPath := filepath.Join(dir, "runs")
+			pluginPath := filepath.Join(dir, "plugin.sh")
+			plugin := "#!/bin/sh\necho run >> " + runsPath + "\ncat <<'EOF'\n" + tc.output + "\nEOF\n"
+			if err = ioutil.WriteFile(pluginPath, []byte(plugin), 0700); err != nil {
+				t.Fatal(err)
+			}
+
+			credentialsFilePath := ""
+			if tc.credentialsFile != "" {
+				credentialsFilePath = filepath.Join(dir, "credentials.yaml")
+				if err = ioutil.WriteFile(credentialsFilePath, []byte(tc.credentialsFile), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for name, value := range tc.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			cloudProvider := kubeone.CloudProviderSpec{
+				Hetzner:         &kubeone.HetznerSpec{},
+				CredentialsExec: &kubeone.CredentialsExecConfig{Command: pluginPath},
+			}
+
+			for i := 0; i < 2; i++ {
+				creds, err := ProviderCredentials(cloudProvider, credentialsFilePath)
+				if tc.expectedError {
+					if err == nil {
+						t.Fatal("expected error, got nil")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(creds, tc.expected) {
+					t.Errorf("expected credentials %v, got %v", tc.expected, creds)
+				}
+			}
+
+			runs, err := ioutil.ReadFile(runsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := strings.Count(string(runs), "run"); n != 1 {
+				t.Errorf("expected the plugin to run once, ran %d times", n)
+			}
+		})
+	}
+}
+
+func TestProviderCredentialsExecFailure(t *testing.T) {
+	cloudProvider := kubeone.CloudProviderSpec{
+		Hetzner:         &kubeone.HetznerSpec{},
+		CredentialsExec: &kubeone.CredentialsExecConfig{Command: "false"},
+	}
+
+	if _, err := ProviderCredentials(cloudProvider, ""); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
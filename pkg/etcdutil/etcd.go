@@ -27,7 +27,7 @@ import (
 	"google.golang.org/grpc"
 
 	"k8c.io/kubeone/pkg/apis/kubeone"
-	"k8c.io/kubeone/pkg/ssh"
+	"k8c.io/kubeone/pkg/runner"
 	"k8c.io/kubeone/pkg/ssh/sshtunnel"
 	"k8c.io/kubeone/pkg/state"
 )
@@ -45,7 +45,11 @@ func NewClientConfig(s *state.State, host kubeone.HostConfig) (*clientv3.Config,
 		return nil, errors.Wrap(err, "failed to create grpc tunnel dialer")
 	}
 
-	tlsConf, err := LoadTLSConfig(sshconn)
+	tlsConf, err := LoadTLSConfig(&runner.Runner{
+		Conn:                sshconn,
+		PrivilegeEscalation: host.PrivilegeEscalation,
+		SudoPasswordFile:    host.SudoPasswordFile,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -65,21 +69,21 @@ func NewClientConfig(s *state.State, host kubeone.HostConfig) (*clientv3.Config,
 // LoadTLSConfig creates the tls.Config structure used securely connect to etcd,
 // certificates and key are downloaded over SSH from the
 // /etc/kubernetes/pki/etcd/ directory.
-func LoadTLSConfig(conn ssh.Connection) (*tls.Config, error) {
+func LoadTLSConfig(r *runner.Runner) (*tls.Config, error) {
 	// Download CA
-	caCertPem, _, _, err := conn.Exec("sudo cat /etc/kubernetes/pki/etcd/ca.crt")
+	caCertPem, _, err := r.RunRawIdempotent("sudo cat /etc/kubernetes/pki/etcd/ca.crt")
 	if err != nil {
 		return nil, err
 	}
 
 	// Download cert
-	certPem, _, _, err := conn.Exec("sudo cat /etc/kubernetes/pki/etcd/server.crt")
+	certPem, _, err := r.RunRawIdempotent("sudo cat /etc/kubernetes/pki/etcd/server.crt")
 	if err != nil {
 		return nil, err
 	}
 
 	// Download key
-	keyPem, _, _, err := conn.Exec("sudo cat /etc/kubernetes/pki/etcd/server.key")
+	keyPem, _, err := r.RunRawIdempotent("sudo cat /etc/kubernetes/pki/etcd/server.key")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,294 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	kubeonev1beta1 "k8c.io/kubeone/pkg/apis/kubeone/v1beta1"
+)
+
+var (
+	// controlPlaneGroups are the groups of the control plane hosts, with the
+	// Kubespray group names accepted as the aliases
+	controlPlaneGroups = []string{"kube_control_plane", "kube_master", "kube-master"}
+	// workerGroups are the groups of the static worker hosts, with the
+	// Kubespray group names accepted as the aliases
+	workerGroups = []string{"kube_workers", "kube_node", "kube-node"}
+)
+
+// Apply adds the hosts from the inventory to the given cluster config, with
+// the same precedence as the Terraform output: the control plane hosts
+// replace the control plane hosts from the manifest, and the static worker
+// hosts are appended to the static worker hosts from the manifest. Hosts in
+// both the control plane and the worker groups are the control plane hosts.
+func (inv *Inventory) Apply(cluster *kubeonev1beta1.KubeOneCluster) error {
+	cpNames := inv.groupsHosts(controlPlaneGroups, nil)
+	isControlPlane := map[string]bool{}
+	for _, name := range cpNames {
+		isControlPlane[name] = true
+	}
+	workerNames := inv.groupsHosts(workerGroups, isControlPlane)
+
+	cpHosts, err := inv.hostConfigs(cpNames, 0)
+	if err != nil {
+		return err
+	}
+	staticWorkers, err := inv.hostConfigs(workerNames, len(cpHosts))
+	if err != nil {
+		return err
+	}
+
+	if len(cpHosts) > 0 {
+		cluster.ControlPlane.Hosts = cpHosts
+	}
+	cluster.StaticWorkers.Hosts = append(cluster.StaticWorkers.Hosts, staticWorkers...)
+
+	return nil
+}
+
+func (inv *Inventory) groupsHosts(groups []string, skip map[string]bool) []string {
+	hosts := []string{}
+	for _, g := range groups {
+		for _, host := range inv.Hosts(g) {
+			if !skip[host] {
+				hosts = appendUnique(hosts, host)
+			}
+		}
+	}
+
+	return hosts
+}
+
+func (inv *Inventory) hostConfigs(names []string, firstID int) ([]kubeonev1beta1.HostConfig, error) {
+	hosts := []kubeonev1beta1.HostConfig{}
+	for i, name := range names {
+		host, err := newHostConfig(name, inv.HostVars(name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "host %q", name)
+		}
+		host.ID = firstID + i
+		hosts = append(hosts, host)
+	}
+
+	return hosts, nil
+}
+
+// newHostConfig maps the Ansible connection variables to the HostConfig. The
+// private address is taken from the ip variable, like in Kubespray, and
+// defaults to the public address.
+func newHostConfig(name string, vars map[string]string) (kubeonev1beta1.HostConfig, error) {
+	publicAddress := firstVar(vars, "ansible_host", "ansible_ssh_host")
+	if publicAddress == "" {
+		publicAddress = name
+	}
+	privateAddress := vars["ip"]
+	if privateAddress == "" {
+		privateAddress = publicAddress
+	}
+
+	host := kubeonev1beta1.HostConfig{
+		PublicAddress:     publicAddress,
+		PrivateAddress:    privateAddress,
+		SSHUsername:       firstVar(vars, "ansible_user", "ansible_ssh_user"),
+		SSHPrivateKeyFile: vars["ansible_ssh_private_key_file"],
+	}
+
+	if port := firstVar(vars, "ansible_port", "ansible_ssh_port"); port != "" {
+		var err error
+		if host.SSHPort, err = strconv.Atoi(port); err != nil {
+			return host, errors.Errorf("invalid ansible_port %q", port)
+		}
+	}
+
+	bastions, err := parseSSHArgs(vars["ansible_ssh_common_args"] + " " + vars["ansible_ssh_extra_args"])
+	if err != nil {
+		return host, err
+	}
+
+	switch {
+	case len(bastions) == 1 && bastions[0].SSHPrivateKeyFile == "":
+		host.Bastion = bastions[0].Address
+		host.BastionPort = bastions[0].Port
+		host.BastionUser = bastions[0].User
+	case len(bastions) > 0:
+		host.Bastions = bastions
+	}
+
+	return host, nil
+}
+
+func firstVar(vars map[string]string, names ...string) string {
+	for _, name := range names {
+		if value := vars[name]; value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// parseSSHArgs returns the bastions from the ProxyJump (-J) and the
+// ProxyCommand options of the SSH arguments
+func parseSSHArgs(args string) ([]kubeonev1beta1.BastionConfig, error) {
+	words, err := splitWords(args)
+	if err != nil {
+		return nil, err
+	}
+
+	bastions := []kubeonev1beta1.BastionConfig{}
+	for i := 0; i < len(words); i++ {
+		var option string
+		switch {
+		case words[i] == "-J" && i+1 < len(words):
+			i++
+			option = "proxyjump=" + words[i]
+		case words[i] == "-o" && i+1 < len(words):
+			i++
+			option = words[i]
+		case strings.HasPrefix(words[i], "-o"):
+			option = strings.TrimPrefix(words[i], "-o")
+		default:
+			continue
+		}
+
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 {
+			kv = strings.SplitN(option, " ", 2)
+		}
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "proxyjump":
+			jumps, err := parseProxyJump(strings.TrimSpace(kv[1]))
+			if err != nil {
+				return nil, err
+			}
+			bastions = append(bastions, jumps...)
+		case "proxycommand":
+			bastion, err := parseProxyCommand(strings.TrimSpace(kv[1]))
+			if err != nil {
+				return nil, err
+			}
+			bastions = append(bastions, bastion)
+		}
+	}
+
+	return bastions, nil
+}
+
+// parseProxyJump parses the comma separated [user@]host[:port] jump hosts
+func parseProxyJump(value string) ([]kubeonev1beta1.BastionConfig, error) {
+	bastions := []kubeonev1beta1.BastionConfig{}
+	for _, jump := range strings.Split(value, ",") {
+		bastion, err := parseDestination(strings.TrimPrefix(jump, "ssh://"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ProxyJump %q", value)
+		}
+		bastions = append(bastions, bastion)
+	}
+
+	return bastions, nil
+}
+
+// parseProxyCommand parses the ssh -W %h:%p ProxyCommand. Other proxy
+// commands, such as nc, are not supported.
+func parseProxyCommand(value string) (kubeonev1beta1.BastionConfig, error) {
+	bastion := kubeonev1beta1.BastionConfig{}
+
+	words, err := splitWords(value)
+	if err != nil {
+		return bastion, err
+	}
+	if len(words) == 0 || path.Base(words[0]) != "ssh" {
+		return bastion, errors.Errorf("unsupported ProxyCommand %q, only ssh is supported", value)
+	}
+
+	destination := ""
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") {
+			destination = word
+			continue
+		}
+		if len(word) != 2 || !strings.ContainsAny(word[1:], "pliWoJ") {
+			// flags without the arguments, such as -q
+			continue
+		}
+		if i+1 >= len(words) {
+			return bastion, errors.Errorf("missing the argument of %s in the ProxyCommand %q", word, value)
+		}
+		i++
+
+		switch word {
+		case "-p":
+			if bastion.Port, err = strconv.Atoi(words[i]); err != nil {
+				return bastion, errors.Errorf("invalid port %q in the ProxyCommand %q", words[i], value)
+			}
+		case "-l":
+			bastion.User = words[i]
+		case "-i":
+			bastion.SSHPrivateKeyFile = words[i]
+		}
+	}
+
+	if destination == "" {
+		return bastion, errors.Errorf("missing the destination in the ProxyCommand %q", value)
+	}
+
+	parsed, err := parseDestination(destination)
+	if err != nil {
+		return bastion, errors.Wrapf(err, "invalid ProxyCommand %q", value)
+	}
+	bastion.Address = parsed.Address
+	if parsed.User != "" {
+		bastion.User = parsed.User
+	}
+	if parsed.Port != 0 {
+		bastion.Port = parsed.Port
+	}
+
+	return bastion, nil
+}
+
+// parseDestination parses the [user@]host[:port] destination
+func parseDestination(destination string) (kubeonev1beta1.BastionConfig, error) {
+	bastion := kubeonev1beta1.BastionConfig{}
+
+	if i := strings.LastIndex(destination, "@"); i >= 0 {
+		bastion.User, destination = destination[:i], destination[i+1:]
+	}
+	if i := strings.LastIndex(destination, ":"); i >= 0 && strings.Count(destination, ":") == 1 {
+		port, err := strconv.Atoi(destination[i+1:])
+		if err != nil {
+			return bastion, errors.Errorf("invalid port %q", destination[i+1:])
+		}
+		bastion.Port, destination = port, destination[:i]
+	}
+	if destination == "" {
+		return bastion, errors.New("missing the host")
+	}
+	bastion.Address = destination
+
+	return bastion, nil
+}
@@ -0,0 +1,432 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inventory sources the KubeOneCluster hosts from the Ansible
+// inventories, in the INI or the YAML format.
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	allGroup       = "all"
+	ungroupedGroup = "ungrouped"
+)
+
+// Inventory is the Ansible inventory
+type Inventory struct {
+	groups map[string]*group
+	// hostVars are the variables of the hosts, set in the host lines
+	hostVars map[string]map[string]string
+}
+
+type group struct {
+	hosts    []string
+	children []string
+	vars     map[string]string
+}
+
+// Load reads the Ansible inventory. Files with the .yaml, .yml and .json
+// extensions are parsed as YAML, and other files as INI.
+func Load(path string) (*Inventory, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the inventory file")
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml", ".json":
+		inv, err := ParseYAML(content)
+		return inv, errors.Wrapf(err, "unable to parse the inventory file %q", path)
+	default:
+		inv, err := ParseINI(content)
+		return inv, errors.Wrapf(err, "unable to parse the inventory file %q", path)
+	}
+}
+
+func newInventory() *Inventory {
+	inv := &Inventory{
+		groups:   map[string]*group{},
+		hostVars: map[string]map[string]string{},
+	}
+	inv.group(allGroup)
+
+	return inv
+}
+
+func (inv *Inventory) group(name string) *group {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &group{vars: map[string]string{}}
+		inv.groups[name] = g
+	}
+
+	return g
+}
+
+func (inv *Inventory) addHost(groupName, host string, vars map[string]string) {
+	g := inv.group(groupName)
+	g.hosts = appendUnique(g.hosts, host)
+
+	if inv.hostVars[host] == nil {
+		inv.hostVars[host] = map[string]string{}
+	}
+	for k, v := range vars {
+		inv.hostVars[host][k] = v
+	}
+}
+
+func (inv *Inventory) addChild(groupName, child string) {
+	g := inv.group(groupName)
+	g.children = appendUnique(g.children, child)
+	inv.group(child)
+}
+
+// ParseINI parses the Ansible inventory in the INI format. Host ranges, such
+// as node[01:10], are not supported.
+func ParseINI(content []byte) (*Inventory, error) {
+	inv := newInventory()
+
+	section, kind := ungroupedGroup, "hosts"
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section, kind = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]"), "hosts"
+			if i := strings.LastIndex(section, ":"); i >= 0 {
+				section, kind = section[:i], section[i+1:]
+			}
+			if kind != "hosts" && kind != "vars" && kind != "children" {
+				return nil, errors.Errorf("line %d: unknown section type %q", lineNo, kind)
+			}
+			inv.group(section)
+			continue
+		}
+
+		switch kind {
+		case "children":
+			inv.addChild(section, stripComment(line))
+		case "vars":
+			kv := strings.SplitN(line, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.Errorf("line %d: expected key=value, got %q", lineNo, line)
+			}
+			value := strings.TrimSpace(kv[1])
+			if words, err := splitWords(value); err == nil && len(words) == 1 {
+				// quoted values are unquoted
+				value = words[0]
+			}
+			inv.group(section).vars[strings.TrimSpace(kv[0])] = value
+		default:
+			words, err := splitWords(stripComment(line))
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", lineNo)
+			}
+			host := words[0]
+			if strings.Contains(host, "[") {
+				return nil, errors.Errorf("line %d: host ranges are not supported, got %q", lineNo, host)
+			}
+
+			vars := map[string]string{}
+			for _, word := range words[1:] {
+				kv := strings.SplitN(word, "=", 2)
+				if len(kv) != 2 {
+					return nil, errors.Errorf("line %d: expected key=value, got %q", lineNo, word)
+				}
+				vars[kv[0]] = kv[1]
+			}
+			inv.addHost(section, host, vars)
+		}
+	}
+
+	return inv, errors.WithStack(scanner.Err())
+}
+
+// ParseYAML parses the Ansible inventory in the YAML format
+func ParseYAML(content []byte) (*Inventory, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	inv := newInventory()
+	for _, item := range doc {
+		if err := inv.addYAMLGroup(fmt.Sprint(item.Key), item.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return inv, nil
+}
+
+func (inv *Inventory) addYAMLGroup(name string, value interface{}) error {
+	inv.group(name)
+	if value == nil {
+		return nil
+	}
+
+	obj, ok := value.(yaml.MapSlice)
+	if !ok {
+		return errors.Errorf("group %q must be a map", name)
+	}
+
+	for _, item := range obj {
+		entries, ok := item.Value.(yaml.MapSlice)
+		if !ok && item.Value != nil {
+			return errors.Errorf("%s of the group %q must be a map", item.Key, name)
+		}
+
+		switch item.Key {
+		case "hosts":
+			for _, host := range entries {
+				hostVars, ok := host.Value.(yaml.MapSlice)
+				if !ok && host.Value != nil {
+					return errors.Errorf("variables of the host %q must be a map", host.Key)
+				}
+				inv.addHost(name, fmt.Sprint(host.Key), yamlVars(hostVars))
+			}
+		case "vars":
+			for k, v := range yamlVars(entries) {
+				inv.group(name).vars[k] = v
+			}
+		case "children":
+			for _, child := range entries {
+				childName := fmt.Sprint(child.Key)
+				inv.addChild(name, childName)
+				if err := inv.addYAMLGroup(childName, child.Value); err != nil {
+					return err
+				}
+			}
+		default:
+			return errors.Errorf("unknown key %q in the group %q", item.Key, name)
+		}
+	}
+
+	return nil
+}
+
+func yamlVars(obj yaml.MapSlice) map[string]string {
+	vars := map[string]string{}
+	for _, item := range obj {
+		if item.Value != nil {
+			vars[fmt.Sprint(item.Key)] = fmt.Sprint(item.Value)
+		}
+	}
+
+	return vars
+}
+
+// Hosts returns the hosts of the group, including the hosts of its
+// descendant groups, in the order of appearance
+func (inv *Inventory) Hosts(groupName string) []string {
+	hosts := []string{}
+	visited := map[string]bool{}
+
+	var walk func(name string)
+	walk = func(name string) {
+		g, ok := inv.groups[name]
+		if !ok || visited[name] {
+			return
+		}
+		visited[name] = true
+
+		for _, host := range g.hosts {
+			hosts = appendUnique(hosts, host)
+		}
+		for _, child := range g.children {
+			walk(child)
+		}
+	}
+	walk(groupName)
+
+	return hosts
+}
+
+// HostVars returns the variables of the host. Like in Ansible, the variables
+// of the parent groups are overridden by the variables of the child groups,
+// which are overridden by the host variables. Groups at the same depth are
+// applied by name.
+func (inv *Inventory) HostVars(host string) map[string]string {
+	depths := inv.groupDepths()
+
+	groups := []string{}
+	for name := range inv.groups {
+		for _, member := range inv.Hosts(name) {
+			if member == host {
+				groups = append(groups, name)
+				break
+			}
+		}
+	}
+	groups = appendUnique(groups, allGroup)
+
+	sort.Slice(groups, func(i, j int) bool {
+		if depths[groups[i]] != depths[groups[j]] {
+			return depths[groups[i]] < depths[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+
+	vars := map[string]string{}
+	for _, name := range groups {
+		for k, v := range inv.groups[name].vars {
+			vars[k] = v
+		}
+	}
+	for k, v := range inv.hostVars[host] {
+		vars[k] = v
+	}
+
+	return vars
+}
+
+// groupDepths returns the distance of the groups from the all group. Groups
+// which aren't children of other groups are children of the all group.
+func (inv *Inventory) groupDepths() map[string]int {
+	isChild := map[string]bool{}
+	for _, g := range inv.groups {
+		for _, child := range g.children {
+			isChild[child] = true
+		}
+	}
+
+	depths := map[string]int{allGroup: 0}
+	queue := []string{}
+	for name := range inv.groups {
+		if name != allGroup && !isChild[name] {
+			depths[name] = 1
+			queue = append(queue, name)
+		}
+	}
+	for _, child := range inv.groups[allGroup].children {
+		depths[child] = 1
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, child := range inv.groups[name].children {
+			if _, ok := depths[child]; !ok {
+				depths[child] = depths[name] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return depths
+}
+
+func appendUnique(list []string, item string) []string {
+	for _, existing := range list {
+		if existing == item {
+			return list
+		}
+	}
+
+	return append(list, item)
+}
+
+// stripComment strips the trailing comment from the line, starting with the
+// unquoted # at the beginning of a word, like Ansible does for the host lines
+func stripComment(line string) string {
+	var quote rune
+	escaped := false
+	prev := ' '
+
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '#' && (prev == ' ' || prev == '\t'):
+			return strings.TrimSpace(line[:i])
+		}
+		prev = r
+	}
+
+	return line
+}
+
+// splitWords splits the line into the words like the shell does, with the
+// single and double quotes grouping the words
+func splitWords(line string) ([]string, error) {
+	words := []string{}
+
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.Errorf("unterminated quote in %q", line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"reflect"
+	"testing"
+
+	kubeonev1beta1 "k8c.io/kubeone/pkg/apis/kubeone/v1beta1"
+)
+
+const iniInventory = `
+# kubespray-like inventory
+[all:vars]
+ansible_user=root
+
+[kube_control_plane]
+cp1 ansible_host=203.0.113.1 ip=10.0.0.1
+cp2 ansible_host=203.0.113.2 ip=10.0.0.2 ansible_port=2222
+
+[kube_node]
+cp1
+worker1 ansible_host=203.0.113.3 ansible_ssh_private_key_file=~/.ssh/worker
+
+[k8s_cluster:children]
+kube_control_plane
+kube_node
+
+[kube_node:vars]
+ansible_user=ubuntu
+ansible_ssh_common_args='-o ProxyCommand="ssh -W %h:%p -q -p 2200 jump@bastion.example.com"'
+`
+
+const yamlInventory = `
+all:
+  vars:
+    ansible_user: root
+  children:
+    k8s_cluster:
+      children:
+        kube_control_plane:
+          hosts:
+            cp1:
+              ansible_host: 203.0.113.1
+              ip: 10.0.0.1
+            cp2:
+              ansible_host: 203.0.113.2
+              ip: 10.0.0.2
+              ansible_port: 2222
+        kube_node:
+          hosts:
+            cp1:
+            worker1:
+              ansible_host: 203.0.113.3
+              ansible_ssh_private_key_file: ~/.ssh/worker
+          vars:
+            ansible_user: ubuntu
+            ansible_ssh_common_args: -o ProxyCommand="ssh -W %h:%p -q -p 2200 jump@bastion.example.com"
+`
+
+func TestApply(t *testing.T) {
+	manifestWorker := kubeonev1beta1.HostConfig{PublicAddress: "198.51.100.1", PrivateAddress: "198.51.100.1"}
+	inventoryHosts := struct {
+		cp      []kubeonev1beta1.HostConfig
+		workers []kubeonev1beta1.HostConfig
+	}{
+		cp: []kubeonev1beta1.HostConfig{
+			{ID: 0, PublicAddress: "203.0.113.1", PrivateAddress: "10.0.0.1", SSHUsername: "ubuntu", Bastion: "bastion.example.com", BastionPort: 2200, BastionUser: "jump"},
+			{ID: 1, PublicAddress: "203.0.113.2", PrivateAddress: "10.0.0.2", SSHUsername: "root", SSHPort: 2222},
+		},
+		workers: []kubeonev1beta1.HostConfig{
+			manifestWorker,
+			{ID: 2, PublicAddress: "203.0.113.3", PrivateAddress: "203.0.113.3", SSHUsername: "ubuntu", SSHPrivateKeyFile: "~/.ssh/worker", Bastion: "bastion.example.com", BastionPort: 2200, BastionUser: "jump"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		parse         func([]byte) (*Inventory, error)
+		inventory     string
+		expectedCP    []kubeonev1beta1.HostConfig
+		expectedSW    []kubeonev1beta1.HostConfig
+		expectedError bool
+	}{
+		{
+			name:       "INI inventory",
+			parse:      ParseINI,
+			inventory:  iniInventory,
+			expectedCP: inventoryHosts.cp,
+			expectedSW: inventoryHosts.workers,
+		},
+		{
+			name:       "YAML inventory",
+			parse:      ParseYAML,
+			inventory:  yamlInventory,
+			expectedCP: inventoryHosts.cp,
+			expectedSW: inventoryHosts.workers,
+		},
+		{
+			name:  "ProxyJump chain",
+			parse: ParseINI,
+			inventory: `
+[kube_control_plane]
+cp1 ansible_ssh_common_args="-J admin@jump1:2200,jump2"
+`,
+			expectedCP: []kubeonev1beta1.HostConfig{
+				{
+					PublicAddress:  "cp1",
+					PrivateAddress: "cp1",
+					Bastions: []kubeonev1beta1.BastionConfig{
+						{Address: "jump1", Port: 2200, User: "admin"},
+						{Address: "jump2"},
+					},
+				},
+			},
+			expectedSW: []kubeonev1beta1.HostConfig{manifestWorker},
+		},
+		{
+			name:  "manifest control plane is kept without the inventory control plane",
+			parse: ParseINI,
+			inventory: `
+[kube_workers]
+worker1 ansible_host=203.0.113.3 ansible_user=ubuntu
+`,
+			expectedCP: []kubeonev1beta1.HostConfig{{PublicAddress: "192.0.2.1", PrivateAddress: "192.0.2.1"}},
+			expectedSW: []kubeonev1beta1.HostConfig{
+				manifestWorker,
+				{PublicAddress: "203.0.113.3", PrivateAddress: "203.0.113.3", SSHUsername: "ubuntu"},
+			},
+		},
+		{
+			name:  "trailing comments",
+			parse: ParseINI,
+			inventory: `
+[kube_workers]
+worker1 ansible_host=203.0.113.3 ansible_user=ubuntu # rack 3
+worker2 ansible_host=203.0.113.4 ansible_ssh_common_args="-J admin#1@jump" # rack #4
+`,
+			expectedCP: []kubeonev1beta1.HostConfig{{PublicAddress: "192.0.2.1", PrivateAddress: "192.0.2.1"}},
+			expectedSW: []kubeonev1beta1.HostConfig{
+				manifestWorker,
+				{PublicAddress: "203.0.113.3", PrivateAddress: "203.0.113.3", SSHUsername: "ubuntu"},
+				{ID: 1, PublicAddress: "203.0.113.4", PrivateAddress: "203.0.113.4", Bastion: "jump", BastionUser: "admin#1"},
+			},
+		},
+		{
+			name:          "host ranges",
+			parse:         ParseINI,
+			inventory:     "[kube_workers]\nworker[01:10]\n",
+			expectedError: true,
+		},
+		{
+			name:          "unsupported ProxyCommand",
+			parse:         ParseINI,
+			inventory:     "[kube_workers]\nworker1 ansible_ssh_common_args='-o ProxyCommand=\"nc -x proxy:1080 %h %p\"'\n",
+			expectedError: true,
+		},
+		{
+			name:          "invalid port",
+			parse:         ParseYAML,
+			inventory:     "kube_workers:\n  hosts:\n    worker1:\n      ansible_port: ssh\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &kubeonev1beta1.KubeOneCluster{
+				ControlPlane:  kubeonev1beta1.ControlPlaneConfig{Hosts: []kubeonev1beta1.HostConfig{{PublicAddress: "192.0.2.1", PrivateAddress: "192.0.2.1"}}},
+				StaticWorkers: kubeonev1beta1.StaticWorkersConfig{Hosts: []kubeonev1beta1.HostConfig{manifestWorker}},
+			}
+
+			inv, err := tc.parse([]byte(tc.inventory))
+			if err == nil {
+				err = inv.Apply(cluster)
+			}
+			if tc.expectedError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(cluster.ControlPlane.Hosts, tc.expectedCP) {
+				t.Errorf("expected control plane hosts:\n%+v\ngot:\n%+v", tc.expectedCP, cluster.ControlPlane.Hosts)
+			}
+			if !reflect.DeepEqual(cluster.StaticWorkers.Hosts, tc.expectedSW) {
+				t.Errorf("expected static worker hosts:\n%+v\ngot:\n%+v", tc.expectedSW, cluster.StaticWorkers.Hosts)
+			}
+		})
+	}
+}
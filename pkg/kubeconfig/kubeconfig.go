@@ -22,7 +22,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	"k8c.io/kubeone/pkg/ssh"
+	"k8c.io/kubeone/pkg/runner"
 	"k8c.io/kubeone/pkg/state"
 )
 
@@ -39,11 +39,15 @@ func Download(s *state.State) ([]byte, error) {
 		return nil, err
 	}
 
-	return CatKubernetesAdminConf(conn)
+	return CatKubernetesAdminConf(&runner.Runner{
+		Conn:                conn,
+		PrivilegeEscalation: host.PrivilegeEscalation,
+		SudoPasswordFile:    host.SudoPasswordFile,
+	})
 }
 
-func CatKubernetesAdminConf(conn ssh.Connection) ([]byte, error) {
-	konfig, _, _, err := conn.Exec("sudo cat /etc/kubernetes/admin.conf")
+func CatKubernetesAdminConf(r *runner.Runner) ([]byte, error) {
+	konfig, _, err := r.RunRawIdempotent("sudo cat /etc/kubernetes/admin.conf")
 	if err != nil {
 		return nil, err
 	}
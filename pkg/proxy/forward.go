@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Forward is the local port forwarded to the remote address
+type Forward struct {
+	LocalPort  int
+	RemoteAddr string
+}
+
+// ParseForward parses the forward in the <local-port>:<host>:<port> format.
+// IPv6 hosts are enclosed in square brackets.
+func ParseForward(spec string) (Forward, error) {
+	idx := strings.Index(spec, ":")
+	if idx < 0 {
+		return Forward{}, errors.Errorf("invalid forward %q, must be <local-port>:<host>:<port>", spec)
+	}
+
+	localPort, err := parsePort(spec[:idx])
+	if err != nil {
+		return Forward{}, errors.Wrapf(err, "invalid forward %q", spec)
+	}
+
+	host, port, err := net.SplitHostPort(spec[idx+1:])
+	if err != nil {
+		return Forward{}, errors.Wrapf(err, "invalid forward %q, must be <local-port>:<host>:<port>", spec)
+	}
+	if host == "" {
+		return Forward{}, errors.Errorf("invalid forward %q, host is missing", spec)
+	}
+
+	remotePort, err := parsePort(port)
+	if err != nil {
+		return Forward{}, errors.Wrapf(err, "invalid forward %q", spec)
+	}
+
+	return Forward{
+		LocalPort:  localPort,
+		RemoteAddr: net.JoinHostPort(host, strconv.Itoa(remotePort)),
+	}, nil
+}
+
+func parsePort(port string) (int, error) {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return 0, errors.Errorf("invalid port %q", port)
+	}
+
+	return p, nil
+}
+
+// ServeForward accepts connections on the listener, and forwards them to the
+// remote address
+func ServeForward(ctx context.Context, listener net.Listener, dial DialFunc, remoteAddr string, logger logrus.FieldLogger) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			destConn, err := dial(ctx, "tcp", remoteAddr)
+			if err != nil {
+				logger.Errorf("Forwarding to %s failed: %v", remoteAddr, err)
+				conn.Close()
+				return
+			}
+
+			pipe(conn, destConn, logger)
+		}()
+	}
+}
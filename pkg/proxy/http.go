@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxy implements HTTP and SOCKS5 proxy servers and port forwarding,
+// dialing the addresses using the given dial function (e.g. over an SSH
+// tunnel).
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// DialFunc dials the address, e.g. tunneled through the SSH connection
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+type httpError struct {
+	err  error
+	code int
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("error: %s, code: %d", e.err, e.code)
+}
+
+// NewHTTPHandler creates the HTTP proxy handler, tunneling HTTPS (CONNECT
+// method) requests, and forwarding plain HTTP requests
+func NewHTTPHandler(dial DialFunc, logger logrus.FieldLogger) http.Handler {
+	forwarder := &httputil.ReverseProxy{
+		// requests to the proxy carry the absolute target URL already
+		Director: func(*http.Request) {},
+		Transport: &http.Transport{
+			DialContext: dial,
+		},
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+			logger.Errorf("Forwarding request failed: %v", err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		},
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			if !r.URL.IsAbs() {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+
+			forwarder.ServeHTTP(w, r)
+			return
+		}
+
+		if terr := handleTunneling(w, r, dial, logger); terr != nil {
+			code := http.StatusInternalServerError
+			if err1, ok := terr.(*httpError); ok {
+				code = err1.code
+			}
+			http.Error(w, terr.Error(), code)
+		}
+	})
+}
+
+func handleTunneling(w http.ResponseWriter, r *http.Request, dial DialFunc, logger logrus.FieldLogger) error {
+	destConn, err := dial(r.Context(), "tcp", r.Host)
+	if err != nil {
+		return &httpError{err: err, code: http.StatusServiceUnavailable}
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		destConn.Close()
+		return &httpError{err: errors.New("hijacking is not supported"), code: http.StatusInternalServerError}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		destConn.Close()
+		return &httpError{err: err, code: http.StatusServiceUnavailable}
+	}
+
+	pipe(clientConn, destConn, logger)
+
+	return nil
+}
+
+// pipe copies data between the connections in both directions, closing both
+// of them when either side is done
+func pipe(clientConn, destConn net.Conn, logger logrus.FieldLogger) {
+	go func() {
+		if err := iocopy(destConn, clientConn); err != nil && !isClosedConnError(err) {
+			logger.Errorf("%v", err)
+		}
+	}()
+
+	go func() {
+		if err := iocopy(clientConn, destConn); err != nil && !isClosedConnError(err) {
+			logger.Errorf("%v", err)
+		}
+	}()
+}
+
+// isClosedConnError reports whether the error is caused by the connection
+// closed by the other copying direction being done
+func isClosedConnError(err error) bool {
+	return strings.Contains(err.Error(), "use of closed network connection")
+}
+
+func iocopy(dst io.WriteCloser, src io.ReadCloser) error {
+	defer dst.Close()
+	defer src.Close()
+
+	_, err := io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,331 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestLogger() logrus.FieldLogger {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	return logger
+}
+
+// newEchoServer starts the TCP server echoing the data back to the client
+func newEchoServer(t *testing.T, network, addr string) net.Listener {
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		t.Skipf("unable to listen on %s: %v", addr, err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return l
+}
+
+func socks5Connect(t *testing.T, proxyAddr string, request []byte) (net.Conn, byte) {
+	conn, err := net.Dial("tcp", proxyAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = conn.Write([]byte{socks5Version, 1, socks5AuthNone}); err != nil {
+		t.Fatal(err)
+	}
+	method := make([]byte, 2)
+	if _, err = io.ReadFull(conn, method); err != nil {
+		t.Fatal(err)
+	}
+	if method[1] != socks5AuthNone {
+		t.Fatalf("expected no authentication method, but got %d", method[1])
+	}
+
+	if _, err = conn.Write(request); err != nil {
+		t.Fatal(err)
+	}
+	reply := make([]byte, 10)
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, reply[1]
+}
+
+func TestSOCKS5(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	dialer := &net.Dialer{}
+	go func() {
+		_ = ServeSOCKS5(context.Background(), listener, dialer.DialContext, newTestLogger())
+	}()
+
+	ipv4Echo := newEchoServer(t, "tcp4", "127.0.0.1:0")
+	ipv4Port := ipv4Echo.Addr().(*net.TCPAddr).Port
+
+	port := func(p int) []byte {
+		b := make([]byte, 2)
+		binary.BigEndian.PutUint16(b, uint16(p))
+		return b
+	}
+
+	tests := []struct {
+		name          string
+		request       func(t *testing.T) []byte
+		expectedReply byte
+	}{
+		{
+			name: "IPv4 address",
+			request: func(t *testing.T) []byte {
+				req := []byte{socks5Version, socks5CmdConnect, 0x00, socks5AddrIPv4, 127, 0, 0, 1}
+				return append(req, port(ipv4Port)...)
+			},
+			expectedReply: socks5ReplySucceeded,
+		},
+		{
+			name: "IPv6 address",
+			request: func(t *testing.T) []byte {
+				l := newEchoServer(t, "tcp6", "[::1]:0")
+				req := append([]byte{socks5Version, socks5CmdConnect, 0x00, socks5AddrIPv6}, net.IPv6loopback...)
+				return append(req, port(l.Addr().(*net.TCPAddr).Port)...)
+			},
+			expectedReply: socks5ReplySucceeded,
+		},
+		{
+			name: "domain name",
+			request: func(t *testing.T) []byte {
+				domain := "localhost"
+				req := append([]byte{socks5Version, socks5CmdConnect, 0x00, socks5AddrDomain, byte(len(domain))}, domain...)
+				return append(req, port(ipv4Port)...)
+			},
+			expectedReply: socks5ReplySucceeded,
+		},
+		{
+			name: "unsupported command",
+			request: func(t *testing.T) []byte {
+				req := []byte{socks5Version, 0x02, 0x00, socks5AddrIPv4, 127, 0, 0, 1}
+				return append(req, port(ipv4Port)...)
+			},
+			expectedReply: socks5ReplyCmdNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, reply := socks5Connect(t, listener.Addr().String(), tt.request(t))
+			defer conn.Close()
+
+			if reply != tt.expectedReply {
+				t.Fatalf("expected reply %d, but got %d", tt.expectedReply, reply)
+			}
+			if reply != socks5ReplySucceeded {
+				return
+			}
+
+			if _, err := conn.Write([]byte("ping")); err != nil {
+				t.Fatal(err)
+			}
+			buf := make([]byte, 4)
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != "ping" {
+				t.Fatalf("expected echoed %q, but got %q", "ping", buf)
+			}
+		})
+	}
+}
+
+func TestHTTPProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello from backend")
+	}))
+	defer backend.Close()
+
+	dialer := &net.Dialer{}
+	proxyServer := httptest.NewServer(NewHTTPHandler(dialer.DialContext, newTestLogger()))
+	defer proxyServer.Close()
+
+	t.Run("plain HTTP", func(t *testing.T) {
+		proxyURL, _ := url.Parse(proxyServer.URL)
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+		resp, err := client.Get(backend.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "hello from backend" {
+			t.Fatalf("unexpected response %d: %q", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("CONNECT", func(t *testing.T) {
+		echo := newEchoServer(t, "tcp4", "127.0.0.1:0")
+
+		conn, err := net.Dial("tcp", proxyServer.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echo.Addr(), echo.Addr())
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, &http.Request{Method: http.MethodConnect})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, but got %d", http.StatusOK, resp.StatusCode)
+		}
+
+		if _, err = conn.Write([]byte("ping")); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 4)
+		if _, err = io.ReadFull(reader, buf); err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != "ping" {
+			t.Fatalf("expected echoed %q, but got %q", "ping", buf)
+		}
+	})
+
+	t.Run("relative URL", func(t *testing.T) {
+		resp, err := http.Get(proxyServer.URL + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected status %d, but got %d", http.StatusBadRequest, resp.StatusCode)
+		}
+	})
+}
+
+func TestParseForward(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expected    Forward
+		expectedErr bool
+	}{
+		{
+			spec:     "2379:10.0.0.1:2379",
+			expected: Forward{LocalPort: 2379, RemoteAddr: "10.0.0.1:2379"},
+		},
+		{
+			spec:     "8080:localhost:80",
+			expected: Forward{LocalPort: 8080, RemoteAddr: "localhost:80"},
+		},
+		{
+			spec:     "10250:[fd00::1]:10250",
+			expected: Forward{LocalPort: 10250, RemoteAddr: "[fd00::1]:10250"},
+		},
+		{
+			spec:        "2379:10.0.0.1",
+			expectedErr: true,
+		},
+		{
+			spec:        "2379::2379",
+			expectedErr: true,
+		},
+		{
+			spec:        "70000:10.0.0.1:2379",
+			expectedErr: true,
+		},
+		{
+			spec:        "2379:10.0.0.1:http",
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseForward(tt.spec)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, but got %v", tt.expectedErr, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, but got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestServeForward(t *testing.T) {
+	echo := newEchoServer(t, "tcp4", "127.0.0.1:0")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	dialer := &net.Dialer{}
+	go func() {
+		_ = ServeForward(context.Background(), listener, dialer.DialContext, echo.Addr().String(), newTestLogger())
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err = io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Fatalf("expected echoed %q, but got %q", "ping", buf)
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// SOCKS5 protocol constants, as defined in RFC 1928
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthNoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded           = 0x00
+	socks5ReplyGeneralFailure      = 0x01
+	socks5ReplyCmdNotSupported     = 0x07
+	socks5ReplyAddrTypeUnsupported = 0x08
+)
+
+// ServeSOCKS5 accepts connections on the listener and serves them as the
+// SOCKS5 proxy. Only the CONNECT command without authentication is supported.
+func ServeSOCKS5(ctx context.Context, listener net.Listener, dial DialFunc, logger logrus.FieldLogger) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := serveSOCKS5Conn(ctx, conn, dial, logger); err != nil {
+				logger.Errorf("SOCKS5 request failed: %v", err)
+			}
+		}()
+	}
+}
+
+func serveSOCKS5Conn(ctx context.Context, conn net.Conn, dial DialFunc, logger logrus.FieldLogger) error {
+	if err := socks5Handshake(conn); err != nil {
+		conn.Close()
+		return err
+	}
+
+	addr, reply, err := socks5ReadRequest(conn)
+	if err != nil {
+		_ = socks5WriteReply(conn, reply)
+		conn.Close()
+		return err
+	}
+
+	destConn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		_ = socks5WriteReply(conn, socks5ReplyGeneralFailure)
+		conn.Close()
+		return errors.Wrapf(err, "failed to dial %s", addr)
+	}
+
+	if err = socks5WriteReply(conn, socks5ReplySucceeded); err != nil {
+		destConn.Close()
+		conn.Close()
+		return err
+	}
+
+	pipe(conn, destConn, logger)
+
+	return nil
+}
+
+// socks5Handshake negotiates the authentication method, accepting only
+// clients not requiring authentication
+func socks5Handshake(conn net.Conn) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return errors.Wrap(err, "failed to read SOCKS5 greeting")
+	}
+	if header[0] != socks5Version {
+		return errors.Errorf("unsupported SOCKS version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return errors.Wrap(err, "failed to read SOCKS5 authentication methods")
+	}
+
+	for _, method := range methods {
+		if method == socks5AuthNone {
+			_, err := conn.Write([]byte{socks5Version, socks5AuthNone})
+			return err
+		}
+	}
+
+	_, _ = conn.Write([]byte{socks5Version, socks5AuthNoAcceptable})
+
+	return errors.New("SOCKS5 client requires authentication, which is not supported")
+}
+
+// socks5ReadRequest reads the request, returning the address to connect to.
+// In case of an error, the reply code to send to the client is returned.
+func socks5ReadRequest(conn net.Conn) (string, byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", socks5ReplyGeneralFailure, errors.Wrap(err, "failed to read SOCKS5 request")
+	}
+	if header[0] != socks5Version {
+		return "", socks5ReplyGeneralFailure, errors.Errorf("unsupported SOCKS version %d", header[0])
+	}
+	if header[1] != socks5CmdConnect {
+		return "", socks5ReplyCmdNotSupported, errors.Errorf("unsupported SOCKS5 command %d", header[1])
+	}
+
+	var host string
+	switch header[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if header[3] == socks5AddrIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return "", socks5ReplyGeneralFailure, errors.Wrap(err, "failed to read SOCKS5 address")
+		}
+		host = ip.String()
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", socks5ReplyGeneralFailure, errors.Wrap(err, "failed to read SOCKS5 address")
+		}
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", socks5ReplyGeneralFailure, errors.Wrap(err, "failed to read SOCKS5 address")
+		}
+		host = string(domain)
+	default:
+		return "", socks5ReplyAddrTypeUnsupported, errors.Errorf("unsupported SOCKS5 address type %d", header[3])
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", socks5ReplyGeneralFailure, errors.Wrap(err, "failed to read SOCKS5 port")
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), socks5ReplySucceeded, nil
+}
+
+// socks5WriteReply writes the reply with the unspecified bound address, as
+// the address on the remote side of the tunnel is not known
+func socks5WriteReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package redact replaces the known secret values in the logs, the dumped
+// KubeOneCluster manifests and the support bundles.
+package redact
+
+import (
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the secret values
+const Redacted = "REDACTED"
+
+// minSecretLength is the length of the shortest secret value replaced, as
+// replacing shorter values would mangle unrelated content
+const minSecretLength = 4
+
+// Replacer replaces the known secret values with Redacted
+type Replacer struct {
+	replacer *strings.Replacer
+}
+
+// NewReplacer creates the Replacer of the given secret values. Values shorter
+// than 4 characters are not replaced.
+func NewReplacer(secrets []string) *Replacer {
+	sorted := []string{}
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			sorted = append(sorted, secret)
+		}
+	}
+	// longer secrets first, in case one secret contains another
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	oldnew := make([]string, 0, 2*len(sorted))
+	for _, secret := range sorted {
+		oldnew = append(oldnew, secret, Redacted)
+	}
+
+	return &Replacer{replacer: strings.NewReplacer(oldnew...)}
+}
+
+// Replace returns the content with the secret values replaced by Redacted
+func (r *Replacer) Replace(content string) string {
+	return r.replacer.Replace(content)
+}
@@ -17,7 +17,10 @@ limitations under the License.
 package runner
 
 import (
+	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/koron-go/prefixw"
 	"github.com/pkg/errors"
@@ -34,23 +37,27 @@ type Runner struct {
 	Prefix  string
 	OS      kubeoneapi.OperatingSystemName
 	Verbose bool
+	// PrivilegeEscalation is the way of running privileged commands, which
+	// are written using sudo
+	PrivilegeEscalation kubeoneapi.PrivilegeEscalation
+	// SudoPasswordFile is path to the file with the sudo password, used with
+	// the sudo-password privilege escalation
+	SudoPasswordFile string
 }
 
 // TemplateVariables is a render context for templates
 type TemplateVariables map[string]interface{}
 
 func (r *Runner) RunRaw(cmd string) (string, string, error) {
-	if r.Conn == nil {
-		return "", "", errors.New("runner is not tied to an opened SSH connection")
-	}
-
 	if !r.Verbose {
-		stdout, stderr, _, err := r.Conn.Exec(cmd)
+		var stdout, stderr strings.Builder
+
+		_, err := r.Stream(cmd, &stdout, &stderr)
 		if err != nil {
-			err = errors.Wrap(err, stderr)
+			err = errors.Wrap(err, stderr.String())
 		}
 
-		return stdout, stderr, err
+		return strings.TrimSpace(stdout.String()), stderr.String(), err
 	}
 
 	stdout := NewTee(prefixw.New(os.Stdout, r.Prefix))
@@ -60,11 +67,70 @@ func (r *Runner) RunRaw(cmd string) (string, string, error) {
 	defer stderr.Close()
 
 	// run the command
-	_, err := r.Conn.Stream(cmd, stdout, stderr)
+	_, err := r.Stream(cmd, stdout, stderr)
 
 	return stdout.String(), stderr.String(), err
 }
 
+// RunRawIdempotent runs the command like RunRaw, retrying it once after
+// reconnecting if the connection to the host was lost while it was running.
+// The command must be safe to run again, such as reading the host state.
+func (r *Runner) RunRawIdempotent(cmd string) (string, string, error) {
+	if r.Conn == nil {
+		return "", "", errors.New("runner is not tied to an opened SSH connection")
+	}
+
+	var stdout, stderr string
+	err := ssh.RetryOnLost(r.Conn, func() error {
+		var runErr error
+		stdout, stderr, runErr = r.RunRaw(cmd)
+
+		return runErr
+	})
+
+	return stdout, stderr, err
+}
+
+// Stream runs the command using the host's privilege escalation, writing its
+// output to the given writers, and returns its exit code.
+func (r *Runner) Stream(cmd string, stdout io.Writer, stderr io.Writer) (int, error) {
+	if r.Conn == nil {
+		return 0, errors.New("runner is not tied to an opened SSH connection")
+	}
+
+	cmd = scripts.Escalate(cmd, r.PrivilegeEscalation)
+
+	var stdin io.Reader
+	if r.PrivilegeEscalation == kubeoneapi.PrivilegeEscalationSudoPassword {
+		password, err := r.sudoPassword()
+		if err != nil {
+			return 0, err
+		}
+		stdin = strings.NewReader(password + "\n")
+	}
+
+	return r.Conn.POpen(cmd, stdin, stdout, stderr)
+}
+
+// sudoPassword sources the sudo password from the password file, or the
+// environment
+func (r *Runner) sudoPassword() (string, error) {
+	if len(r.SudoPasswordFile) > 0 {
+		password, err := ioutil.ReadFile(r.SudoPasswordFile)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read sudo password file %q", r.SudoPasswordFile)
+		}
+
+		return strings.TrimRight(string(password), "\r\n"), nil
+	}
+
+	if password := os.Getenv(scripts.SudoPasswordEnvVar); len(password) > 0 {
+		return password, nil
+	}
+
+	return "", errors.Errorf("sudo password is required, but not provided (use sudoPasswordFile, or the %s environment variable)", scripts.SudoPasswordEnvVar)
+}
+
 // Run executes a given command/script, optionally printing its output to
 // stdout/stderr.
 func (r *Runner) Run(cmd string, variables TemplateVariables) (string, string, error) {
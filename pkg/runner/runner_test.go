@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh"
+)
+
+// fakeBinaries stand in for sudo and doas, running the command as the
+// current user. The fake sudo requires the password from the askpass helper.
+var fakeBinaries = map[string]string{
+	"sudo": `#!/bin/sh
+[ "$1" = "-A" ] || { echo "sudo: a password is required" >&2; exit 1; }
+shift
+[ "$("$SUDO_ASKPASS")" = "secret" ] || { echo "sudo: incorrect password" >&2; exit 1; }
+exec env "$@"
+`,
+	"doas": `#!/bin/sh
+exec "$@"
+`,
+}
+
+func TestRunRawPrivilegeEscalation(t *testing.T) {
+	binDir := t.TempDir()
+	for name, content := range fakeBinaries {
+		if err := ioutil.WriteFile(filepath.Join(binDir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(passwordFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		escalation       kubeoneapi.PrivilegeEscalation
+		sudoPasswordFile string
+		expectedErr      bool
+	}{
+		{
+			name:       "none",
+			escalation: kubeoneapi.PrivilegeEscalationNone,
+		},
+		{
+			name:       "doas",
+			escalation: kubeoneapi.PrivilegeEscalationDoas,
+		},
+		{
+			name:             "sudo with password",
+			escalation:       kubeoneapi.PrivilegeEscalationSudoPassword,
+			sudoPasswordFile: passwordFile,
+		},
+		{
+			name:        "passwordless sudo when password is required",
+			escalation:  kubeoneapi.PrivilegeEscalationSudo,
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := ssh.NewLocalConnection(ssh.NewConnector(context.Background()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			r := &Runner{
+				Conn:                conn,
+				PrivilegeEscalation: tt.escalation,
+				SudoPasswordFile:    tt.sudoPasswordFile,
+			}
+
+			// stdin of the privileged commands is left intact
+			stdout, _, err := r.RunRaw(`export PATH="` + binDir + `:$PATH"
+echo input | sudo GREETING=hello sh -c 'echo "$GREETING $(cat)"'`)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, but got %v", tt.expectedErr, err)
+			}
+			if !tt.expectedErr && stdout != "hello input" {
+				t.Errorf("expected output %q, but got %q", "hello input", stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scripts
+
+import (
+	"k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+// SudoPasswordEnvVar is the environment variable holding the sudo password,
+// used with the sudo-password privilege escalation
+const SudoPasswordEnvVar = "KUBEONE_SUDO_PASSWORD"
+
+var escalationPreambles = map[kubeone.PrivilegeEscalation]string{
+	// env handles the leading VAR=value arguments, same as sudo does
+	kubeone.PrivilegeEscalationNone: `sudo() { env "$@"; }
+`,
+	kubeone.PrivilegeEscalationDoas: `sudo() { doas env "$@"; }
+`,
+	// the password is read from the first line of stdin and given to sudo
+	// by the askpass helper, so stdin of the commands is left intact and the
+	// password is never part of the command line or written to the disk
+	kubeone.PrivilegeEscalationSudoPassword: `IFS= read -r KUBEONE_SUDO_PASSWORD
+export KUBEONE_SUDO_PASSWORD
+KUBEONE_SUDO_ASKPASS="$(mktemp)"
+trap 'rm -f "$KUBEONE_SUDO_ASKPASS"' EXIT
+cat > "$KUBEONE_SUDO_ASKPASS" <<'EOF'
+#!/bin/sh
+printf '%s\n' "$KUBEONE_SUDO_PASSWORD"
+EOF
+chmod 0700 "$KUBEONE_SUDO_ASKPASS"
+sudo() { SUDO_ASKPASS="$KUBEONE_SUDO_ASKPASS" command sudo -A "$@"; }
+`,
+}
+
+// Escalate adapts the script to run privileged commands using the given
+// privilege escalation. Scripts are written using passwordless sudo, which
+// is replaced by the sudo shell function for other privilege escalations.
+//
+// With the sudo-password privilege escalation, the script expects the sudo
+// password on the first line of stdin.
+func Escalate(cmd string, escalation kubeone.PrivilegeEscalation) string {
+	preamble, ok := escalationPreambles[escalation]
+	if !ok {
+		return cmd
+	}
+
+	return preamble + cmd
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+// passwordRecorder records the passwords offered to the test server
+type passwordRecorder struct {
+	mu        sync.Mutex
+	passwords []string
+}
+
+func (r *passwordRecorder) record(password []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.passwords = append(r.passwords, string(password))
+}
+
+func (r *passwordRecorder) offered(password string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.passwords {
+		if p == password {
+			return true
+		}
+	}
+
+	return false
+}
+
+// passwordAuth accepts only the given username and password
+func passwordAuth(rec *passwordRecorder, username, password string) func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+	return func(meta ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+		rec.record(pass)
+		if meta.User() == username && string(pass) == password {
+			return nil, nil
+		}
+
+		return nil, errors.New("invalid password")
+	}
+}
+
+// publicKeyAuth accepts only the given username and key
+func publicKeyAuth(username string, key ssh.PublicKey) func(ssh.ConnMetadata, ssh.PublicKey) (*ssh.Permissions, error) {
+	return func(meta ssh.ConnMetadata, k ssh.PublicKey) (*ssh.Permissions, error) {
+		if meta.User() == username && bytes.Equal(k.Marshal(), key.Marshal()) {
+			return nil, nil
+		}
+
+		return nil, errors.New("invalid public key")
+	}
+}
+
+func TestBastionChain(t *testing.T) {
+	userSigner, userKey := newTestSigner(t)
+	otherSigner, _ := newTestSigner(t)
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	writeKeyFile(t, keyFile, userKey)
+
+	// the first bastion accepts only its own password, the second one only
+	// the key, and the target host either the password or the key
+	jump1Passwords := &passwordRecorder{}
+	jump1 := newTestServerWithConfig(t, &ssh.ServerConfig{
+		PasswordCallback: passwordAuth(jump1Passwords, "jump1", "bastion-secret"),
+	})
+	defer jump1.listener.Close()
+
+	jump2Passwords := &passwordRecorder{}
+	jump2 := newTestServerWithConfig(t, &ssh.ServerConfig{
+		PasswordCallback:  passwordAuth(jump2Passwords, "", ""),
+		PublicKeyCallback: publicKeyAuth("jump2", userSigner.PublicKey()),
+	})
+	defer jump2.listener.Close()
+
+	targetPasswords := &passwordRecorder{}
+	target := newTestServerWithConfig(t, &ssh.ServerConfig{
+		PasswordCallback:  passwordAuth(targetPasswords, "root", "target-secret"),
+		PublicKeyCallback: publicKeyAuth("root", userSigner.PublicKey()),
+	})
+	defer target.listener.Close()
+
+	pinned := func(s *testServer) string {
+		return string(ssh.MarshalAuthorizedKey(s.hostKey))
+	}
+	jump1Host, jump1Port := jump1.hostPort()
+	jump2Host, jump2Port := jump2.hostPort()
+	targetHost, targetPort := target.hostPort()
+
+	tests := []struct {
+		name             string
+		opts             Opts
+		expectedAccepted []int
+		expectedErr      bool
+	}{
+		{
+			name: "two bastions",
+			opts: Opts{
+				Password: "target-secret",
+				Bastions: []BastionOpts{
+					{Username: "jump1", Password: "bastion-secret", Hostname: jump1Host, Port: jump1Port, HostPublicKey: pinned(jump1)},
+					{Username: "jump2", KeyFile: keyFile, Hostname: jump2Host, Port: jump2Port, HostPublicKey: pinned(jump2)},
+				},
+			},
+			expectedAccepted: []int{1, 1, 1},
+		},
+		{
+			name: "bastion host key mismatch",
+			opts: Opts{
+				Password: "target-secret",
+				Bastions: []BastionOpts{
+					{Username: "jump1", Password: "bastion-secret", Hostname: jump1Host, Port: jump1Port, HostPublicKey: pinned(jump1)},
+					{Username: "jump2", KeyFile: keyFile, Hostname: jump2Host, Port: jump2Port, HostPublicKey: string(ssh.MarshalAuthorizedKey(otherSigner.PublicKey()))},
+				},
+			},
+			expectedAccepted: []int{1, 1, 0},
+			expectedErr:      true,
+		},
+		{
+			name: "target password is not sent to the bastion",
+			opts: Opts{
+				Password: "target-secret",
+				Bastions: []BastionOpts{
+					{Username: "jump1", Hostname: jump1Host, Port: jump1Port, HostPublicKey: pinned(jump1)},
+				},
+			},
+			expectedAccepted: []int{1, 0, 0},
+			expectedErr:      true,
+		},
+		{
+			name: "legacy bastion",
+			opts: Opts{
+				KeyFile:              keyFile,
+				Bastion:              jump2Host,
+				BastionPort:          jump2Port,
+				BastionUser:          "jump2",
+				BastionHostPublicKey: pinned(jump2),
+			},
+			expectedAccepted: []int{0, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := []*testServer{jump1, jump2, target}
+			accepted := make([]int, len(servers))
+			for i, s := range servers {
+				accepted[i] = s.acceptedConnections()
+			}
+
+			o := tt.opts
+			o.Username = "root"
+			o.Hostname = targetHost
+			o.Port = targetPort
+			o.HostPublicKey = pinned(target)
+			o.HostKeyCheck = kubeoneapi.SSHHostKeyCheckStrict
+			o.Timeout = 5 * time.Second
+
+			conn, err := NewConnection(NewConnector(context.Background()), o)
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, but got %v", tt.expectedErr, err)
+			}
+			if err == nil {
+				defer conn.Close()
+
+				if _, _, _, err = conn.Exec("true"); err != nil {
+					t.Fatalf("expected command to succeed, but got %v", err)
+				}
+			}
+
+			for i, s := range servers {
+				if got := s.acceptedConnections() - accepted[i]; got != tt.expectedAccepted[i] {
+					t.Errorf("expected hop #%d to accept %d connections, but it accepted %d", i, tt.expectedAccepted[i], got)
+				}
+			}
+
+			if jump1Passwords.offered("target-secret") || jump2Passwords.offered("target-secret") {
+				t.Error("expected the target host password not to be sent to the bastion hosts")
+			}
+		})
+	}
+}
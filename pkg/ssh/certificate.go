@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// certificateFileSuffix is appended to the private key path to find the
+// certificate when it's not explicitly given, same as OpenSSH does
+const certificateFileSuffix = "-cert.pub"
+
+// loadCertificate loads the OpenSSH user certificate from the certificate
+// file. If the certificate file is not given, the certificate next to the
+// private key file is used if it exists and is valid.
+func loadCertificate(a authOpts) (*ssh.Certificate, error) {
+	filename := a.CertificateFile
+	explicit := len(filename) > 0
+
+	if !explicit {
+		if len(a.KeyFile) == 0 {
+			return nil, nil
+		}
+
+		filename = a.KeyFile + certificateFileSuffix
+		if _, err := os.Stat(filename); err != nil {
+			return nil, nil
+		}
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read SSH certificate %q", filename)
+	}
+
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse SSH certificate %q", filename)
+	}
+
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, errors.Errorf("%q is not an SSH certificate", filename)
+	}
+
+	if err = validateCertificate(cert, time.Now()); err != nil {
+		if !explicit {
+			// ignore invalid certificates not asked for, same as OpenSSH
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "SSH certificate %q can't be used", filename)
+	}
+
+	return cert, nil
+}
+
+func validateCertificate(cert *ssh.Certificate, now time.Time) error {
+	if cert.CertType != ssh.UserCert {
+		return errors.New("not a user certificate")
+	}
+
+	unixNow := uint64(now.Unix())
+	if unixNow < cert.ValidAfter {
+		return errors.Errorf("certificate is not valid before %s", time.Unix(int64(cert.ValidAfter), 0))
+	}
+	if cert.ValidBefore != ssh.CertTimeInfinity && unixNow >= cert.ValidBefore {
+		return errors.Errorf("certificate expired at %s", time.Unix(int64(cert.ValidBefore), 0))
+	}
+
+	return nil
+}
+
+// withCertSigner prepends the certificate signer to the signers, if the
+// certificate is signed for the key of one of the signers. used is set if
+// the certificate signer is added.
+func withCertSigner(signers []ssh.Signer, cert *ssh.Certificate, used *bool) ([]ssh.Signer, error) {
+	if cert == nil {
+		return signers, nil
+	}
+
+	certKey := cert.Key.Marshal()
+	for _, signer := range signers {
+		if !bytes.Equal(signer.PublicKey().Marshal(), certKey) {
+			continue
+		}
+
+		certSigner, err := ssh.NewCertSigner(cert, signer)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create SSH certificate signer")
+		}
+		*used = true
+
+		// the certificate is offered first, as servers often limit the
+		// number of authentication attempts
+		return append([]ssh.Signer{certSigner}, signers...), nil
+	}
+
+	return signers, nil
+}
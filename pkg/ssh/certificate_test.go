@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T) (ssh.Signer, ed25519.PrivateKey) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer, priv
+}
+
+// writeKeyFile writes the private key to the file in the PKCS#8 format
+func writeKeyFile(t *testing.T, filename string, priv ed25519.PrivateKey) {
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeCertificate signs the user certificate for the key by the CA, and
+// writes it to the file
+func writeCertificate(t *testing.T, filename string, key ssh.PublicKey, ca ssh.Signer, validBefore time.Time) {
+	cert := &ssh.Certificate{
+		Key:             key,
+		CertType:        ssh.UserCert,
+		KeyId:           "test",
+		ValidPrincipals: []string{"root"},
+		ValidBefore:     uint64(validBefore.Unix()),
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filename, ssh.MarshalAuthorizedKey(cert), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertificateAuthentication(t *testing.T) {
+	ca, _ := newTestSigner(t)
+	userSigner, userKey := newTestSigner(t)
+	otherSigner, _ := newTestSigner(t)
+
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return bytes.Equal(auth.Marshal(), ca.PublicKey().Marshal())
+		},
+	}
+	server := newTestServerWithConfig(t, &ssh.ServerConfig{
+		PublicKeyCallback: checker.Authenticate,
+	})
+	defer server.listener.Close()
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "id_ed25519")
+	writeKeyFile(t, keyFile, userKey)
+
+	tests := []struct {
+		name            string
+		writeCerts      func(t *testing.T)
+		certificateFile string
+		expectedErr     bool
+	}{
+		{
+			name:        "no certificate",
+			writeCerts:  func(t *testing.T) {},
+			expectedErr: true,
+		},
+		{
+			name: "certificate next to the key",
+			writeCerts: func(t *testing.T) {
+				writeCertificate(t, keyFile+"-cert.pub", userSigner.PublicKey(), ca, time.Now().Add(time.Hour))
+			},
+		},
+		{
+			name: "certificate file",
+			writeCerts: func(t *testing.T) {
+				writeCertificate(t, filepath.Join(dir, "user-cert.pub"), userSigner.PublicKey(), ca, time.Now().Add(time.Hour))
+			},
+			certificateFile: filepath.Join(dir, "user-cert.pub"),
+		},
+		{
+			name: "expired certificate file",
+			writeCerts: func(t *testing.T) {
+				writeCertificate(t, filepath.Join(dir, "expired-cert.pub"), userSigner.PublicKey(), ca, time.Now().Add(-time.Hour))
+			},
+			certificateFile: filepath.Join(dir, "expired-cert.pub"),
+			expectedErr:     true,
+		},
+		{
+			name: "certificate file for another key",
+			writeCerts: func(t *testing.T) {
+				writeCertificate(t, filepath.Join(dir, "other-cert.pub"), otherSigner.PublicKey(), ca, time.Now().Add(time.Hour))
+			},
+			certificateFile: filepath.Join(dir, "other-cert.pub"),
+			expectedErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.writeCerts(t)
+
+			conn, err := server.dial(Opts{
+				KeyFile:         keyFile,
+				CertificateFile: tt.certificateFile,
+			})
+			if (err != nil) != tt.expectedErr {
+				t.Fatalf("expected error %v, but got %v", tt.expectedErr, err)
+			}
+			if err == nil {
+				conn.Close()
+			}
+		})
+	}
+}
@@ -31,12 +31,15 @@ import (
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
 )
 
 const socketEnvPrefix = "env:"
 
 var (
 	_ Tunneler = &connection{}
+	_ Retrier  = &connection{}
 )
 
 // Connection represents an established connection to an SSH server.
@@ -44,6 +47,7 @@ type Connection interface {
 	Exec(cmd string) (stdout string, stderr string, exitCode int, err error)
 	File(filename string, flags int) (io.ReadWriteCloser, error)
 	Stream(cmd string, stdout io.Writer, stderr io.Writer) (exitCode int, err error)
+	POpen(cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (exitCode int, err error)
 	io.Closer
 }
 
@@ -58,18 +62,45 @@ type Tunneler interface {
 // Opts represents all the possible options for connecting to
 // a remote server via SSH.
 type Opts struct {
-	Context     context.Context
-	Username    string
-	Password    string
-	Hostname    string
-	Port        int
-	PrivateKey  string
-	KeyFile     string
-	AgentSocket string
-	Timeout     time.Duration
-	Bastion     string
-	BastionPort int
-	BastionUser string
+	Context                  context.Context
+	Username                 string
+	Password                 string
+	Hostname                 string
+	Port                     int
+	PrivateKey               string
+	KeyFile                  string
+	PrivateKeyPassphraseFile string
+	CertificateFile          string
+	AgentSocket              string
+	Timeout                  time.Duration
+	KeepAliveInterval        time.Duration
+	Bastion                  string
+	BastionPort              int
+	BastionUser              string
+	Bastions                 []BastionOpts
+	HostPublicKey            string
+	BastionHostPublicKey     string
+	KnownHostsFile           string
+	HostKeyCheck             kubeoneapi.SSHHostKeyCheck
+	FileTransfer             kubeoneapi.SSHFileTransfer
+}
+
+// BastionOpts represents options for connecting to a single bastion (or
+// jump) host. Opts.Bastions is a chain of bastion hosts to jump through, in
+// order, to reach the target host. Keys, certificates and the agent socket
+// that are not set are inherited from the target host options. The password
+// is never inherited, so it's not sent to the bastion hosts.
+type BastionOpts struct {
+	Username                 string
+	Password                 string
+	Hostname                 string
+	Port                     int
+	PrivateKey               string
+	KeyFile                  string
+	PrivateKeyPassphraseFile string
+	CertificateFile          string
+	AgentSocket              string
+	HostPublicKey            string
 }
 
 func validateOptions(o Opts) (Opts, error) {
@@ -92,7 +123,6 @@ func validateOptions(o Opts) (Opts, error) {
 		}
 
 		o.PrivateKey = string(content)
-		o.KeyFile = ""
 	}
 
 	if o.Port <= 0 {
@@ -111,16 +141,109 @@ func validateOptions(o Opts) (Opts, error) {
 		o.Timeout = 60 * time.Second
 	}
 
+	if o.KeepAliveInterval == 0 {
+		o.KeepAliveInterval = defaultKeepAliveInterval
+	}
+
+	if o.FileTransfer == "" {
+		o.FileTransfer = kubeoneapi.SSHFileTransferAuto
+	}
+
+	if len(o.Bastions) == 0 && o.Bastion != "" {
+		o.Bastions = []BastionOpts{
+			{
+				Username:      o.BastionUser,
+				Hostname:      o.Bastion,
+				Port:          o.BastionPort,
+				HostPublicKey: o.BastionHostPublicKey,
+			},
+		}
+	}
+
+	bastions := make([]BastionOpts, len(o.Bastions))
+	for i, b := range o.Bastions {
+		if len(b.Hostname) == 0 {
+			return o, errors.Errorf("no hostname specified for bastion host #%d", i)
+		}
+
+		if b.Port <= 0 {
+			b.Port = 22
+		}
+
+		if b.Username == "" {
+			b.Username = o.Username
+		}
+
+		switch {
+		case len(b.KeyFile) > 0:
+			content, err := ioutil.ReadFile(b.KeyFile)
+			if err != nil {
+				return o, errors.Wrapf(err, "failed to read keyfile %q", b.KeyFile)
+			}
+			b.PrivateKey = string(content)
+		case len(b.Password) == 0 && len(b.PrivateKey) == 0 && len(b.AgentSocket) == 0:
+			b.PrivateKey = o.PrivateKey
+			b.KeyFile = o.KeyFile
+			b.PrivateKeyPassphraseFile = o.PrivateKeyPassphraseFile
+			b.CertificateFile = o.CertificateFile
+			b.AgentSocket = o.AgentSocket
+		}
+
+		bastions[i] = b
+	}
+	o.Bastions = bastions
+
 	return o, nil
 }
 
 type connection struct {
-	mu         sync.Mutex
-	sftpclient *sftp.Client
-	sshclient  *ssh.Client
-	connector  *Connector
-	ctx        context.Context
-	cancel     context.CancelFunc
+	mu sync.Mutex
+	// reconnectMu serializes re-dialing the lost connection, and is held
+	// across the dial, unlike mu
+	reconnectMu sync.Mutex
+	sftpclient  *sftp.Client
+	sshclient   *ssh.Client
+	// bastionclients are connections to the bastion hosts, in order of hops
+	bastionclients []*ssh.Client
+	// agentconns are connections to the SSH agents used to authenticate
+	// the hops
+	agentconns []io.Closer
+	// lost is set when the connection to the host is lost, so it's re-dialed
+	// before it's used again
+	lost   bool
+	closed bool
+	// sftpUnavailable is set when the SFTP subsystem is not available on the
+	// host, so files are transferred over exec sessions instead
+	sftpUnavailable bool
+	opts            Opts
+	connector       *Connector
+	ctx             context.Context
+	cancel          context.CancelFunc
+}
+
+// hop is a single SSH server on the way to the target host
+type hop struct {
+	endpoint string
+	config   *ssh.ClientConfig
+	// agentconn is the connection to the SSH agent used to authenticate,
+	// if any
+	agentconn io.Closer
+}
+
+// chain is an established connection to the target host, through all hops
+type chain struct {
+	// clients are connections to the SSH servers, in order of hops
+	clients []*ssh.Client
+	// agentconns are connections to the SSH agents used to authenticate
+	// the hops
+	agentconns []io.Closer
+}
+
+// close closes the SSH clients in reverse order of hops, and the connections
+// to the SSH agents
+func (ch chain) close() {
+	closeClients(ch.clients)
+	closeAll(ch.agentconns)
 }
 
 // NewConnection attempts to create a new SSH connection to the host
@@ -131,145 +254,337 @@ func NewConnection(connector *Connector, o Opts) (Connection, error) {
 		return nil, errors.Wrap(err, "failed to validate ssh connection options")
 	}
 
-	authMethods := make([]ssh.AuthMethod, 0)
+	ch, err := connector.dial(o)
+	if err != nil {
+		return nil, err
+	}
 
-	if len(o.Password) > 0 {
-		authMethods = append(authMethods, ssh.Password(o.Password))
+	ctx, cancelFn := context.WithCancel(connector.ctx)
+	sshConn := &connection{
+		opts:      o,
+		connector: connector,
+		ctx:       ctx,
+		cancel:    cancelFn,
 	}
+	sshConn.mu.Lock()
+	sshConn.setClients(ch)
+	sshConn.mu.Unlock()
 
-	if len(o.PrivateKey) > 0 {
-		signer, parseErr := ssh.ParsePrivateKey([]byte(o.PrivateKey))
-		if parseErr != nil {
-			return nil, errors.Wrap(parseErr, "the given SSH key could not be parsed (note that password-protected keys are not supported)")
+	return sshConn, nil
+}
+
+// dial establishes connections to all bastion hosts and to the target host,
+// returning SSH clients in order of hops
+func (c *Connector) dial(o Opts) (chain, error) {
+	ch := chain{}
+	hops := []hop{}
+
+	for _, b := range o.Bastions {
+		h, hopErr := c.newHop(b.Username, b.Hostname, b.Port, o.Timeout, b.HostPublicKey, o, authOpts{
+			Password:                 b.Password,
+			PrivateKey:               b.PrivateKey,
+			KeyFile:                  b.KeyFile,
+			PrivateKeyPassphraseFile: b.PrivateKeyPassphraseFile,
+			CertificateFile:          b.CertificateFile,
+			AgentSocket:              b.AgentSocket,
+		})
+		if hopErr != nil {
+			ch.close()
+			return chain{}, errors.Wrapf(hopErr, "failed to setup connection to bastion host %s", b.Hostname)
 		}
 
-		authMethods = append(authMethods, ssh.PublicKeys(signer))
+		hops = append(hops, h)
+		if h.agentconn != nil {
+			ch.agentconns = append(ch.agentconns, h.agentconn)
+		}
 	}
 
-	if len(o.AgentSocket) > 0 {
-		addr := o.AgentSocket
+	target, err := c.newHop(o.Username, o.Hostname, o.Port, o.Timeout, o.HostPublicKey, o, authOpts{
+		Password:                 o.Password,
+		PrivateKey:               o.PrivateKey,
+		KeyFile:                  o.KeyFile,
+		PrivateKeyPassphraseFile: o.PrivateKeyPassphraseFile,
+		CertificateFile:          o.CertificateFile,
+		AgentSocket:              o.AgentSocket,
+	})
+	if err != nil {
+		ch.close()
+		return chain{}, err
+	}
+	hops = append(hops, target)
+	if target.agentconn != nil {
+		ch.agentconns = append(ch.agentconns, target.agentconn)
+	}
 
-		if strings.HasPrefix(o.AgentSocket, socketEnvPrefix) {
-			envName := strings.TrimPrefix(o.AgentSocket, socketEnvPrefix)
+	for i, h := range hops {
+		var client *ssh.Client
 
-			if envAddr := os.Getenv(envName); len(envAddr) > 0 {
-				addr = envAddr
+		if i == 0 {
+			client, err = ssh.Dial("tcp", h.endpoint, h.config)
+			if err != nil {
+				ch.close()
+				return chain{}, errors.Wrapf(err, "could not establish connection to %s", h.endpoint)
+			}
+		} else {
+			// Dial a connection to the next hop, from the previous one
+			conn, dialErr := ch.clients[i-1].Dial("tcp", h.endpoint)
+			if dialErr != nil {
+				ch.close()
+				return chain{}, errors.Wrapf(dialErr, "could not establish connection to %s from %s", h.endpoint, hops[i-1].endpoint)
 			}
-		}
 
-		socket, dialErr := net.Dial("unix", addr)
-		if dialErr != nil {
-			return nil, errors.Wrapf(dialErr, "could not open socket %q", addr)
+			ncc, chans, reqs, connErr := ssh.NewClientConn(conn, h.endpoint, h.config)
+			if connErr != nil {
+				conn.Close()
+				ch.close()
+				return chain{}, errors.Wrapf(connErr, "could not establish connection to %s", h.endpoint)
+			}
+
+			client = ssh.NewClient(ncc, chans, reqs)
 		}
 
-		agentClient := agent.NewClient(socket)
+		ch.clients = append(ch.clients, client)
+	}
 
-		signers, signersErr := agentClient.Signers()
-		if signersErr != nil {
-			socket.Close()
-			return nil, errors.Wrap(signersErr, "error when creating signer for SSH agent")
-		}
+	return ch, nil
+}
 
-		authMethods = append(authMethods, ssh.PublicKeys(signers...))
+// closeClients closes the given SSH clients in reverse order of hops
+func closeClients(clients []*ssh.Client) {
+	for i := len(clients) - 1; i >= 0; i-- {
+		clients[i].Close()
 	}
+}
 
-	sshConfig := &ssh.ClientConfig{
-		User:            o.Username,
-		Timeout:         o.Timeout,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(), //nolint:gosec
+// closeAll closes all the given closers
+func closeAll(closers []io.Closer) {
+	for _, c := range closers {
+		c.Close()
 	}
+}
+
+// authOpts are credentials used to authenticate to a single SSH server
+type authOpts struct {
+	Password                 string
+	PrivateKey               string
+	KeyFile                  string
+	PrivateKeyPassphraseFile string
+	CertificateFile          string
+	AgentSocket              string
+}
 
-	targetHost := o.Hostname
-	targetPort := strconv.Itoa(o.Port)
+func (c *Connector) newHop(username, hostname string, port int, timeout time.Duration, hostPublicKey string, o Opts, a authOpts) (_ hop, err error) {
+	authMethods, agentconn, err := c.authMethods(a)
+	if err != nil {
+		return hop{}, err
+	}
+	defer func() {
+		if err != nil && agentconn != nil {
+			agentconn.Close()
+		}
+	}()
 
-	if o.Bastion != "" {
-		targetHost = o.Bastion
-		targetPort = strconv.Itoa(o.BastionPort)
-		sshConfig.User = o.BastionUser
+	verifier, err := newHostKeyVerifier(o.HostKeyCheck, hostPublicKey, o.KnownHostsFile)
+	if err != nil {
+		return hop{}, err
 	}
 
 	// do not use fmt.Sprintf() to allow proper IPv6 handling if hostname is an IP address
-	endpoint := net.JoinHostPort(targetHost, targetPort)
+	endpoint := net.JoinHostPort(hostname, strconv.Itoa(port))
 
-	client, err := ssh.Dial("tcp", endpoint, sshConfig)
+	config, err := verifier.clientConfig(&ssh.ClientConfig{
+		User:    username,
+		Timeout: timeout,
+		Auth:    authMethods,
+	}, endpoint)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+		return hop{}, err
 	}
 
-	ctx, cancelFn := context.WithCancel(connector.ctx)
-	sshConn := &connection{
-		connector: connector,
-		ctx:       ctx,
-		cancel:    cancelFn,
+	return hop{endpoint: endpoint, config: config, agentconn: agentconn}, nil
+}
+
+// authMethods returns the methods to authenticate with the given credentials,
+// and the connection to the SSH agent, if used, which the caller must close
+func (c *Connector) authMethods(a authOpts) ([]ssh.AuthMethod, net.Conn, error) {
+	authMethods := make([]ssh.AuthMethod, 0)
+	var socket net.Conn
+
+	if len(a.Password) > 0 {
+		authMethods = append(authMethods, ssh.Password(a.Password))
 	}
 
-	if o.Bastion == "" {
-		sshConn.sshclient = client
-		// connection established
-		return sshConn, nil
+	cert, err := loadCertificate(a)
+	if err != nil {
+		return nil, nil, err
 	}
+	certUsed := false
 
-	// continue to setup if we are running over bastion
-	endpointBehindBastion := net.JoinHostPort(o.Hostname, strconv.Itoa(o.Port))
+	if len(a.PrivateKey) > 0 {
+		signer, parseErr := c.privateKeySigner(a)
+		if parseErr != nil {
+			return nil, nil, parseErr
+		}
 
-	// Dial a connection to the service host, from the bastion
-	conn, err := client.Dial("tcp", endpointBehindBastion)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
+		signers, certErr := withCertSigner([]ssh.Signer{signer}, cert, &certUsed)
+		if certErr != nil {
+			return nil, nil, certErr
+		}
+
+		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	}
 
-	sshConfig.User = o.Username
-	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpointBehindBastion, sshConfig)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
+	if len(a.AgentSocket) > 0 {
+		addr := a.AgentSocket
+
+		if strings.HasPrefix(a.AgentSocket, socketEnvPrefix) {
+			envName := strings.TrimPrefix(a.AgentSocket, socketEnvPrefix)
+
+			if envAddr := os.Getenv(envName); len(envAddr) > 0 {
+				addr = envAddr
+			}
+		}
+
+		var dialErr error
+		socket, dialErr = net.Dial("unix", addr)
+		if dialErr != nil {
+			return nil, nil, errors.Wrapf(dialErr, "could not open socket %q", addr)
+		}
+
+		agentClient := agent.NewClient(socket)
+
+		// certificates held by the agent are included in the signers
+		signers, signersErr := agentClient.Signers()
+		if signersErr != nil {
+			socket.Close()
+			return nil, nil, errors.Wrap(signersErr, "error when creating signer for SSH agent")
+		}
+
+		signers, signersErr = withCertSigner(signers, cert, &certUsed)
+		if signersErr != nil {
+			socket.Close()
+			return nil, nil, signersErr
+		}
+
+		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	}
 
-	sshConn.sshclient = ssh.NewClient(ncc, chans, reqs)
-	return sshConn, nil
+	if cert != nil && len(a.CertificateFile) > 0 && !certUsed {
+		if socket != nil {
+			socket.Close()
+		}
+		return nil, nil, errors.Errorf("SSH certificate %q is not signed for the private key, nor for any key held by the SSH agent", a.CertificateFile)
+	}
+
+	return authMethods, socket, nil
 }
 
 // File return remote file (as an io.ReadWriteCloser).
 //
 // mode is os package file modes: https://golang.org/pkg/os/#pkg-constants
 // returned file optionally implement
+//
+// Files are accessed using SFTP, or streamed over exec sessions if the SFTP
+// subsystem is not available on the host (see Opts.FileTransfer).
+//
+// Opening the file is retried once if the connection to the host was lost.
 func (c *connection) File(filename string, flags int) (io.ReadWriteCloser, error) {
-	sftpClient, err := c.sftp()
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to open SFTP")
-	}
+	var file io.ReadWriteCloser
+
+	err := c.RetryOnLost(func() error {
+		if !c.execTransfer() {
+			sftpClient, err := c.sftp()
+			if err == nil {
+				f, openErr := sftpClient.OpenFile(filename, flags)
+				if openErr != nil {
+					return openErr
+				}
+				file = f
+
+				return nil
+			}
 
-	return sftpClient.OpenFile(filename, flags)
+			if !c.execTransfer() {
+				return errors.Wrap(err, "failed to open SFTP")
+			}
+		}
+
+		f, err := c.execFile(filename, flags)
+		if err != nil {
+			return err
+		}
+		file = f
+
+		return nil
+	})
+
+	return file, err
 }
 
 func (c *connection) TunnelTo(_ context.Context, network, addr string) (net.Conn, error) {
 	// the voided context.Context is voided as a workaround of always Done
 	// context that being passed. Please don't try to <-ctx.Done(), it will
 	// always return immediately
-	netconn, err := c.sshclient.Dial(network, addr)
+	var netconn net.Conn
+
+	err := c.RetryOnLost(func() error {
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+
+		netconn, err = client.Dial(network, addr)
+
+		return err
+	})
 	if err == nil {
 		go func() {
 			<-c.ctx.Done()
 			netconn.Close()
 		}()
 	}
+
 	return netconn, err
 }
 
 func (c *connection) Close() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.cancel()
+	err := c.closeClients()
+	c.mu.Unlock()
+
+	// the connector lock is taken only after releasing c.mu, as the connector
+	// checks the connection state while holding its lock
+	c.connector.forgetConnection(c)
+
+	return err
+}
 
+// closeClients closes connections to the host, the bastion hosts and the SSH
+// agents. Must be called with c.mu held.
+func (c *connection) closeClients() error {
 	if c.sshclient == nil {
 		return nil
 	}
-	c.cancel()
 
-	defer func() { c.sshclient = nil }()
-	defer func() { c.sftpclient = nil }()
-	defer c.connector.forgetConnection(c)
+	if c.sftpclient != nil {
+		c.sftpclient.Close()
+	}
+	err := c.sshclient.Close()
+	closeClients(c.bastionclients)
+	closeAll(c.agentconns)
+
+	c.sftpclient = nil
+	c.sshclient = nil
+	c.bastionclients = nil
+	c.agentconns = nil
 
-	return c.sshclient.Close()
+	return err
 }
 
 func (c *connection) POpen(cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
@@ -307,28 +622,61 @@ func (c *connection) Exec(cmd string) (string, string, int, error) {
 	return strings.TrimSpace(stdoutBuf.String()), stderrBuf.String(), exitCode, err
 }
 
+// session opens a new session. It's retried once if the connection to the
+// host was lost, which is safe as the command is not started at that point.
+// Commands interrupted by losing the connection are retried only if run using
+// ExecIdempotent or RetryOnLost, as they are not known to be idempotent.
 func (c *connection) session() (*ssh.Session, error) {
+	var sess *ssh.Session
+
+	err := c.RetryOnLost(func() error {
+		client, err := c.client()
+		if err != nil {
+			return err
+		}
+
+		sess, err = client.NewSession()
+
+		return err
+	})
+
+	return sess, err
+}
+
+func (c *connection) client() (*ssh.Client, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.sshclient == nil {
+	if c.closed {
 		return nil, errors.New("connection closed")
 	}
 
-	return c.sshclient.NewSession()
+	if c.lost {
+		return nil, errConnectionLost
+	}
+
+	return c.sshclient, nil
 }
 
 func (c *connection) sftp() (*sftp.Client, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.sshclient == nil {
+	if c.closed {
 		return nil, errors.New("connection closed")
 	}
 
+	if c.lost {
+		return nil, errConnectionLost
+	}
+
 	if c.sftpclient == nil {
-		s, err := sftp.NewClient(c.sshclient)
+		s, err := newSFTPClient(c.sshclient)
 		if err != nil {
+			if errors.Is(err, errSFTPUnavailable) && c.opts.FileTransfer == kubeoneapi.SSHFileTransferAuto {
+				c.sftpUnavailable = true
+			}
+
 			return nil, errors.Wrap(err, "failed to get sftp.Client")
 		}
 		c.sftpclient = s
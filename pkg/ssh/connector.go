@@ -18,19 +18,30 @@ package ssh
 
 import (
 	"context"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 
 	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh/sshconfig"
 )
 
+// DialFunc establishes a Connection to the given host
+type DialFunc func(host kubeoneapi.HostConfig) (Connection, error)
+
 // Connector holds a map of Connections
 type Connector struct {
 	lock        sync.Mutex
 	connections map[int]Connection
 	ctx         context.Context
+	sshConfigs  map[string]*sshconfig.Config
+	dialFunc    DialFunc
+
+	signersLock sync.Mutex
+	signers     map[string]ssh.Signer
 }
 
 // NewConnector constructor
@@ -38,9 +49,20 @@ func NewConnector(ctx context.Context) *Connector {
 	return &Connector{
 		connections: make(map[int]Connection),
 		ctx:         ctx,
+		sshConfigs:  make(map[string]*sshconfig.Config),
+		signers:     make(map[string]ssh.Signer),
 	}
 }
 
+// NewConnectorWithDialer creates a Connector establishing connections using
+// the given function instead of SSH, e.g. to simulate hosts in tests
+func NewConnectorWithDialer(ctx context.Context, dial DialFunc) *Connector {
+	c := NewConnector(ctx)
+	c.dialFunc = dial
+
+	return c
+}
+
 // Tunnel returns established SSH tunnel
 func (c *Connector) Tunnel(host kubeoneapi.HostConfig) (Tunneler, error) {
 	conn, err := c.Connect(host)
@@ -64,8 +86,34 @@ func (c *Connector) Connect(host kubeoneapi.HostConfig) (Connection, error) {
 	defer c.lock.Unlock()
 
 	conn, found := c.connections[host.ID]
-	if !found {
-		opts := sshOpts(host)
+	if found {
+		// re-dial connections lost in the meanwhile, e.g. by rebooting the host
+		if sshConn, ok := conn.(*connection); ok && sshConn.isLost() {
+			if err = sshConn.reconnect(); err != nil {
+				return nil, err
+			}
+		}
+	} else if c.dialFunc != nil {
+		conn, err = c.dialFunc(host)
+		if err != nil {
+			return nil, err
+		}
+
+		c.connections[host.ID] = conn
+	} else if host.Connection == kubeoneapi.HostConnectionLocal {
+		conn, err = NewLocalConnection(c)
+		if err != nil {
+			return nil, err
+		}
+
+		c.connections[host.ID] = conn
+	} else {
+		var opts Opts
+
+		opts, err = c.sshOpts(host)
+		if err != nil {
+			return nil, err
+		}
 		opts.Context = c.ctx
 		conn, err = NewConnection(c, opts)
 		if err != nil {
@@ -78,7 +126,7 @@ func (c *Connector) Connect(host kubeoneapi.HostConfig) (Connection, error) {
 	return conn, nil
 }
 
-func (c *Connector) forgetConnection(conn *connection) {
+func (c *Connector) forgetConnection(conn Connection) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -89,16 +137,128 @@ func (c *Connector) forgetConnection(conn *connection) {
 	}
 }
 
-func sshOpts(host kubeoneapi.HostConfig) Opts {
-	return Opts{
-		Username:    host.SSHUsername,
-		Port:        host.SSHPort,
-		Hostname:    host.PublicAddress,
-		KeyFile:     host.SSHPrivateKeyFile,
-		AgentSocket: host.SSHAgentSocket,
-		Timeout:     10 * time.Second,
-		Bastion:     host.Bastion,
-		BastionPort: host.BastionPort,
-		BastionUser: host.BastionUser,
+func (c *Connector) sshOpts(host kubeoneapi.HostConfig) (Opts, error) {
+	bastions := []BastionOpts{}
+	for _, b := range host.Bastions {
+		bastions = append(bastions, BastionOpts{
+			Username:                 b.User,
+			Hostname:                 b.Address,
+			Port:                     b.Port,
+			KeyFile:                  b.SSHPrivateKeyFile,
+			PrivateKeyPassphraseFile: b.SSHPrivateKeyPassphraseFile,
+			CertificateFile:          b.SSHCertificateFile,
+			AgentSocket:              b.SSHAgentSocket,
+			HostPublicKey:            b.HostPublicKey,
+		})
+	}
+
+	opts := Opts{
+		Username:                 host.SSHUsername,
+		Port:                     host.SSHPort,
+		Hostname:                 host.PublicAddress,
+		KeyFile:                  host.SSHPrivateKeyFile,
+		PrivateKeyPassphraseFile: host.SSHPrivateKeyPassphraseFile,
+		CertificateFile:          host.SSHCertificateFile,
+		AgentSocket:              host.SSHAgentSocket,
+		Timeout:                  10 * time.Second,
+		Bastion:                  host.Bastion,
+		BastionPort:              host.BastionPort,
+		BastionUser:              host.BastionUser,
+		Bastions:                 bastions,
+		HostPublicKey:            host.SSHHostPublicKey,
+		BastionHostPublicKey:     host.BastionHostPublicKey,
+		KnownHostsFile:           host.SSHKnownHostsFile,
+		HostKeyCheck:             host.SSHHostKeyCheck,
+		FileTransfer:             host.SSHFileTransfer,
+	}
+
+	if host.SSHConfigFile != "" {
+		if err := c.resolveSSHConfig(&opts, host); err != nil {
+			return opts, errors.Wrapf(err, "failed to resolve host %q from the ssh config", host.PublicAddress)
+		}
+	}
+
+	return opts, nil
+}
+
+// resolveSSHConfig fills the options not explicitly configured for the host
+// with the settings from the ssh_config file, using the public address as the
+// host alias. Must be called with c.lock held.
+func (c *Connector) resolveSSHConfig(opts *Opts, host kubeoneapi.HostConfig) error {
+	cfg, ok := c.sshConfigs[host.SSHConfigFile]
+	if !ok {
+		var err error
+		cfg, err = sshconfig.Load(host.SSHConfigFile)
+		if err != nil {
+			return err
+		}
+		c.sshConfigs[host.SSHConfigFile] = cfg
+	}
+
+	resolved, err := cfg.Resolve(host.PublicAddress, host.SSHUsername)
+	if err != nil {
+		return err
+	}
+
+	opts.Hostname = resolved.HostName
+	if opts.Username == "" {
+		opts.Username = defaultString(resolved.User, "root")
+	}
+	if opts.Port == 0 {
+		opts.Port = resolved.Port
+	}
+	if opts.KeyFile == "" {
+		opts.KeyFile = firstExistingFile(resolved.IdentityFiles)
+	}
+	if opts.CertificateFile == "" {
+		opts.CertificateFile = firstExistingFile(resolved.CertificateFiles)
+	}
+	if opts.KeyFile == "" && opts.AgentSocket == "" {
+		opts.AgentSocket = socketEnvPrefix + "SSH_AUTH_SOCK"
+	}
+
+	if opts.Bastion != "" || len(opts.Bastions) > 0 {
+		return nil
+	}
+
+	// jump hosts are resolved through the ssh_config file too
+	for _, jump := range resolved.ProxyJump {
+		jumpHost, err := cfg.Resolve(jump.Host, jump.User)
+		if err != nil {
+			return err
+		}
+
+		port := jump.Port
+		if port == 0 {
+			port = jumpHost.Port
+		}
+
+		opts.Bastions = append(opts.Bastions, BastionOpts{
+			Username:        jumpHost.User,
+			Hostname:        jumpHost.HostName,
+			Port:            port,
+			KeyFile:         firstExistingFile(jumpHost.IdentityFiles),
+			CertificateFile: firstExistingFile(jumpHost.CertificateFiles),
+		})
+	}
+
+	return nil
+}
+
+func firstExistingFile(paths []string) string {
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
 	}
+
+	return ""
+}
+
+func defaultString(value, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+
+	return defaultValue
 }
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+var errSFTPUnavailable = errors.New("SFTP subsystem is not available")
+
+// newSFTPClient starts the SFTP subsystem on the host. Failing to negotiate
+// the subsystem, e.g. because it's disabled in sshd_config, is reported as
+// errSFTPUnavailable.
+func newSFTPClient(client *ssh.Client) (*sftp.Client, error) {
+	sess, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sess.RequestSubsystem("sftp"); err != nil {
+		sess.Close()
+		return nil, errors.Wrap(errSFTPUnavailable, err.Error())
+	}
+
+	pw, err := sess.StdinPipe()
+	if err != nil {
+		sess.Close()
+		return nil, err
+	}
+
+	pr, err := sess.StdoutPipe()
+	if err != nil {
+		sess.Close()
+		return nil, err
+	}
+
+	s, err := sftp.NewClientPipe(pr, pw)
+	if err != nil {
+		// the subsystem is configured, but the sftp-server is missing or
+		// exits right away
+		sess.Close()
+		return nil, errors.Wrap(errSFTPUnavailable, err.Error())
+	}
+
+	return s, nil
+}
+
+// execTransfer returns whether files are transferred over exec sessions
+// instead of SFTP
+func (c *connection) execTransfer() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.opts.FileTransfer == kubeoneapi.SSHFileTransferExec || c.sftpUnavailable
+}
+
+// execFile opens the remote file streamed over exec sessions. The content is
+// read when the file is opened, and written when the file is closed.
+func (c *connection) execFile(filename string, flags int) (io.ReadWriteCloser, error) {
+	f := &execFile{
+		conn:     c,
+		name:     filename,
+		readable: flags&os.O_WRONLY == 0,
+		writable: flags&(os.O_WRONLY|os.O_RDWR) != 0,
+		append:   flags&os.O_APPEND != 0,
+	}
+
+	quoted := shellQuote(filename)
+	mustExist := flags&os.O_CREATE == 0
+	// writes without O_TRUNC and O_APPEND overwrite the content from the
+	// beginning, same as with SFTP, so the original content is needed
+	load := flags&os.O_TRUNC == 0 && (f.readable || !f.append)
+
+	var cmd string
+	switch {
+	case load && mustExist:
+		cmd = "cat -- " + quoted
+	case load:
+		cmd = "if [ -e " + quoted + " ]; then cat -- " + quoted + "; fi"
+	case mustExist:
+		cmd = "test -e " + quoted
+	default:
+		return f, nil
+	}
+
+	var stdout bytes.Buffer
+	var stderr strings.Builder
+	if _, err := c.POpen(cmd, nil, &stdout, &stderr); err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s: %s", filename, strings.TrimSpace(stderr.String()))
+	}
+
+	f.original = stdout.Bytes()
+	f.reader = bytes.NewReader(f.original)
+
+	return f, nil
+}
+
+// execFile is a remote file streamed over exec sessions
+type execFile struct {
+	conn     *connection
+	name     string
+	readable bool
+	writable bool
+	append   bool
+	original []byte
+	reader   *bytes.Reader
+	written  bytes.Buffer
+}
+
+func (f *execFile) Read(p []byte) (int, error) {
+	if !f.readable {
+		return 0, errors.Errorf("file %s is not opened for reading", f.name)
+	}
+	if f.reader == nil {
+		return 0, io.EOF
+	}
+
+	return f.reader.Read(p)
+}
+
+func (f *execFile) Write(p []byte) (int, error) {
+	if !f.writable {
+		return 0, errors.Errorf("file %s is not opened for writing", f.name)
+	}
+
+	return f.written.Write(p)
+}
+
+func (f *execFile) Close() error {
+	if !f.writable {
+		return nil
+	}
+
+	content := f.written.Bytes()
+	redirect := ">"
+	if f.append {
+		redirect = ">>"
+	} else if len(f.original) > len(content) {
+		content = append(content, f.original[len(content):]...)
+	}
+
+	var stderr strings.Builder
+	cmd := "cat " + redirect + " " + shellQuote(f.name)
+	if _, err := f.conn.POpen(cmd, bytes.NewReader(content), ioutil.Discard, &stderr); err != nil {
+		return errors.Wrapf(err, "failed to write %s: %s", f.name, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// shellQuote quotes the string to be used as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+func TestExecFileTransfer(t *testing.T) {
+	tests := []struct {
+		name         string
+		fileTransfer kubeoneapi.SSHFileTransfer
+		initial      string
+		flags        int
+		write        string
+		want         string
+	}{
+		{
+			name:         "fallback when SFTP is not available",
+			fileTransfer: kubeoneapi.SSHFileTransferAuto,
+			flags:        os.O_RDWR | os.O_CREATE | os.O_TRUNC,
+			write:        "content\x00with binary\n",
+			want:         "content\x00with binary\n",
+		},
+		{
+			name:         "truncate existing file",
+			fileTransfer: kubeoneapi.SSHFileTransferExec,
+			initial:      "old content",
+			flags:        os.O_WRONLY | os.O_TRUNC,
+			write:        "new",
+			want:         "new",
+		},
+		{
+			name:         "overwrite existing file",
+			fileTransfer: kubeoneapi.SSHFileTransferExec,
+			initial:      "old content",
+			flags:        os.O_WRONLY,
+			write:        "new",
+			want:         "new content",
+		},
+		{
+			name:         "append to existing file",
+			fileTransfer: kubeoneapi.SSHFileTransferExec,
+			initial:      "old content",
+			flags:        os.O_WRONLY | os.O_APPEND,
+			write:        ", new content",
+			want:         "old content, new content",
+		},
+	}
+
+	server := newTestServer(t)
+	defer server.listener.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "it's a file")
+			if tt.initial != "" {
+				if err := ioutil.WriteFile(filename, []byte(tt.initial), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			conn := server.connect(t, Opts{FileTransfer: tt.fileTransfer})
+			defer conn.Close()
+
+			w, err := conn.File(filename, tt.flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = io.WriteString(w, tt.write); err != nil {
+				t.Fatal(err)
+			}
+			if err = w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := conn.File(filename, os.O_RDONLY)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expected content %q, but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExecFileTransferMissingFile(t *testing.T) {
+	server := newTestServer(t)
+	defer server.listener.Close()
+
+	conn := server.connect(t, Opts{FileTransfer: kubeoneapi.SSHFileTransferExec})
+	defer conn.Close()
+
+	if _, err := conn.File(filepath.Join(t.TempDir(), "missing"), os.O_RDONLY); err == nil {
+		t.Error("expected opening missing file to fail")
+	}
+}
+
+func TestSFTPFileTransferUnavailable(t *testing.T) {
+	server := newTestServer(t)
+	defer server.listener.Close()
+
+	conn := server.connect(t, Opts{FileTransfer: kubeoneapi.SSHFileTransferSFTP})
+	defer conn.Close()
+
+	if _, err := conn.File(filepath.Join(t.TempDir(), "file"), os.O_RDWR|os.O_CREATE); err == nil {
+		t.Error("expected opening file to fail when only SFTP is allowed")
+	}
+}
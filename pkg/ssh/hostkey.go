@@ -0,0 +1,263 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+const defaultKnownHostsFile = "~/.ssh/known_hosts"
+
+var (
+	// knownHostsLock serializes writes to known_hosts files, as connections
+	// to different hosts are established in parallel
+	knownHostsLock sync.Mutex
+
+	probeKeyOnce sync.Once
+	probeKey     ssh.PublicKey
+	probeKeyErr  error
+)
+
+// hostKeyVerifier verifies host keys presented by SSH servers, either against
+// the pinned host key, or against the known_hosts file
+type hostKeyVerifier struct {
+	check          kubeoneapi.SSHHostKeyCheck
+	pinnedKey      ssh.PublicKey
+	knownHostsFile string
+}
+
+func newHostKeyVerifier(check kubeoneapi.SSHHostKeyCheck, pinnedKey, knownHostsFile string) (*hostKeyVerifier, error) {
+	v := &hostKeyVerifier{
+		check:          check,
+		knownHostsFile: knownHostsFile,
+	}
+
+	if len(pinnedKey) > 0 {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pinnedKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse pinned SSH host public key")
+		}
+		v.pinnedKey = key
+	}
+
+	if v.check == "" {
+		v.check = kubeoneapi.SSHHostKeyCheckIgnore
+	}
+
+	if v.pinnedKey == nil && v.check != kubeoneapi.SSHHostKeyCheckIgnore {
+		if v.knownHostsFile == "" {
+			v.knownHostsFile = defaultKnownHostsFile
+		}
+
+		path, err := expandHomeDir(v.knownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		v.knownHostsFile = path
+	}
+
+	return v, nil
+}
+
+// clientConfig returns a copy of the given ssh.ClientConfig setup to verify
+// the host key of the given address
+func (v *hostKeyVerifier) clientConfig(base *ssh.ClientConfig, addr string) (*ssh.ClientConfig, error) {
+	cfg := *base
+
+	switch {
+	case v.pinnedKey != nil:
+		cfg.HostKeyCallback = v.pinnedCallback
+		cfg.HostKeyAlgorithms = []string{v.pinnedKey.Type()}
+	case v.check == kubeoneapi.SSHHostKeyCheckIgnore:
+		cfg.HostKeyCallback = ssh.InsecureIgnoreHostKey() //nolint:gosec
+	default:
+		if v.check == kubeoneapi.SSHHostKeyCheckTrustOnFirstUse {
+			if err := ensureKnownHostsFile(v.knownHostsFile); err != nil {
+				return nil, err
+			}
+		}
+
+		callback, err := knownhosts.New(v.knownHostsFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read known_hosts file %q", v.knownHostsFile)
+		}
+
+		cfg.HostKeyCallback = v.knownHostsCallback(callback)
+		cfg.HostKeyAlgorithms = knownHostKeyAlgorithms(callback, addr)
+	}
+
+	return &cfg, nil
+}
+
+func (v *hostKeyVerifier) pinnedCallback(hostname string, _ net.Addr, key ssh.PublicKey) error {
+	if bytes.Equal(key.Marshal(), v.pinnedKey.Marshal()) {
+		return nil
+	}
+
+	return errors.Errorf(
+		"SSH host key verification failed for %s: expected %s key with fingerprint %s, but the host presented %s key with fingerprint %s",
+		hostname,
+		v.pinnedKey.Type(),
+		ssh.FingerprintSHA256(v.pinnedKey),
+		key.Type(),
+		ssh.FingerprintSHA256(key),
+	)
+}
+
+func (v *hostKeyVerifier) knownHostsCallback(callback ssh.HostKeyCallback) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		keyErr, ok := err.(*knownhosts.KeyError)
+		if !ok {
+			return err
+		}
+
+		if len(keyErr.Want) > 0 {
+			known := keyErr.Want[0]
+			return errors.Errorf(
+				"SSH host key verification failed for %s: the host presented %s key with fingerprint %s, "+
+					"but %s:%d contains a different key. Someone could be eavesdropping on you right now (man-in-the-middle attack), "+
+					"or the host key has just been changed. Remove the offending key from %q if you trust the new one",
+				hostname,
+				key.Type(),
+				ssh.FingerprintSHA256(key),
+				known.Filename,
+				known.Line,
+				known.Filename,
+			)
+		}
+
+		if v.check != kubeoneapi.SSHHostKeyCheckTrustOnFirstUse {
+			return errors.Errorf(
+				"SSH host key verification failed for %s: no host key found in %q (presented %s key with fingerprint %s)",
+				hostname,
+				v.knownHostsFile,
+				key.Type(),
+				ssh.FingerprintSHA256(key),
+			)
+		}
+
+		return v.trust(hostname, remote, key)
+	}
+}
+
+// trust records the given host key in the known_hosts file
+func (v *hostKeyVerifier) trust(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	// another connection might have recorded the key in the meanwhile
+	callback, err := knownhosts.New(v.knownHostsFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read known_hosts file %q", v.knownHostsFile)
+	}
+	if err = callback(hostname, remote, key); err == nil {
+		return nil
+	} else if keyErr, ok := err.(*knownhosts.KeyError); !ok || len(keyErr.Want) > 0 {
+		return v.knownHostsCallback(callback)(hostname, remote, key)
+	}
+
+	f, err := os.OpenFile(v.knownHostsFile, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open known_hosts file %q", v.knownHostsFile)
+	}
+	defer f.Close()
+
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)
+	if _, err = f.WriteString(line + "\n"); err != nil {
+		return errors.Wrapf(err, "failed to record host key in %q", v.knownHostsFile)
+	}
+
+	return nil
+}
+
+// knownHostKeyAlgorithms returns the types of host keys known for the given
+// address, so that the server is asked to present a key we are able to verify
+func knownHostKeyAlgorithms(callback ssh.HostKeyCallback, addr string) []string {
+	probeKeyOnce.Do(func() {
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			probeKeyErr = err
+			return
+		}
+		probeKey, probeKeyErr = ssh.NewPublicKey(pub)
+	})
+	if probeKeyErr != nil {
+		return nil
+	}
+
+	// the probe key is random, so the callback can only fail, listing the
+	// known keys for the address
+	keyErr, ok := callback(addr, &net.TCPAddr{}, probeKey).(*knownhosts.KeyError)
+	if !ok {
+		return nil
+	}
+
+	algos := []string{}
+	for _, known := range keyErr.Want {
+		algos = append(algos, known.Key.Type())
+	}
+
+	if len(algos) == 0 {
+		return nil
+	}
+
+	return algos
+}
+
+func ensureKnownHostsFile(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.Wrapf(err, "failed to create directory for known_hosts file %q", path)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create known_hosts file %q", path)
+	}
+
+	return f.Close()
+}
+
+func expandHomeDir(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to expand %q", path)
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func verifyHostKey(t *testing.T, v *hostKeyVerifier, addr string, key ssh.PublicKey) error {
+	cfg, err := v.clientConfig(&ssh.ClientConfig{}, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg.HostKeyCallback(addr, &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 22}, key)
+}
+
+func TestHostKeyVerifier(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-known-hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	knownHosts := filepath.Join(dir, "ssh", "known_hosts")
+	hostKey := newTestHostKey(t)
+	otherKey := newTestHostKey(t)
+
+	strict, err := newHostKeyVerifier(kubeoneapi.SSHHostKeyCheckStrict, "", knownHosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strict.clientConfig(&ssh.ClientConfig{}, "192.168.1.1:22"); err == nil {
+		t.Error("expected strict verification to fail without known_hosts file")
+	}
+
+	tofu, err := newHostKeyVerifier(kubeoneapi.SSHHostKeyCheckTrustOnFirstUse, "", knownHosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyHostKey(t, tofu, "192.168.1.1:22", hostKey); err != nil {
+		t.Errorf("expected unknown host key to be trusted on first use, but got %v", err)
+	}
+	if err = verifyHostKey(t, tofu, "192.168.1.1:22", hostKey); err != nil {
+		t.Errorf("expected recorded host key to be accepted, but got %v", err)
+	}
+	if err = verifyHostKey(t, tofu, "192.168.1.1:22", otherKey); err == nil {
+		t.Error("expected mismatching host key to be rejected")
+	}
+
+	if err = verifyHostKey(t, strict, "192.168.1.1:22", hostKey); err != nil {
+		t.Errorf("expected recorded host key to be accepted, but got %v", err)
+	}
+	if err = verifyHostKey(t, strict, "192.168.1.2:22", hostKey); err == nil {
+		t.Error("expected unknown host to be rejected")
+	}
+
+	pinned, err := newHostKeyVerifier(kubeoneapi.SSHHostKeyCheckIgnore, string(ssh.MarshalAuthorizedKey(otherKey)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyHostKey(t, pinned, "192.168.1.1:22", otherKey); err != nil {
+		t.Errorf("expected pinned host key to be accepted, but got %v", err)
+	}
+	if err = verifyHostKey(t, pinned, "192.168.1.1:22", hostKey); err == nil {
+		t.Error("expected host key not matching the pinned key to be rejected")
+	}
+}
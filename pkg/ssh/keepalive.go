@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	defaultKeepAliveInterval = 15 * time.Second
+	// keepAliveMaxFailures is number of unanswered keepalive requests after
+	// which the connection is considered lost
+	keepAliveMaxFailures = 3
+	keepAliveRequest     = "keepalive@openssh.com"
+)
+
+var errConnectionLost = errors.New("connection to the host lost")
+
+// Retrier is implemented by the connections which are re-dialed after the
+// connection to the host was lost
+type Retrier interface {
+	// RetryOnLost runs the operation, retrying it once after reconnecting if
+	// it failed because the connection to the host was lost. The operation
+	// must be idempotent.
+	RetryOnLost(fn func() error) error
+}
+
+// RetryOnLost runs the idempotent operation using the connection, retrying it
+// once after reconnecting if the connection to the host was lost. Connections
+// which are not re-dialed run the operation only once.
+func RetryOnLost(conn Connection, fn func() error) error {
+	if r, ok := conn.(Retrier); ok {
+		return r.RetryOnLost(fn)
+	}
+
+	return fn()
+}
+
+// ExecIdempotent runs the command like Exec, retrying it once after
+// reconnecting if the connection to the host was lost while it was running.
+// It's used for the probes and the commands reading the host state, which are
+// safe to run again.
+func ExecIdempotent(conn Connection, cmd string) (stdout string, stderr string, exitCode int, err error) {
+	err = RetryOnLost(conn, func() error {
+		var execErr error
+		stdout, stderr, exitCode, execErr = conn.Exec(cmd)
+
+		return execErr
+	})
+
+	return stdout, stderr, exitCode, err
+}
+
+// setClients replaces SSH clients of the connection and starts sending
+// keepalive requests to the host. Must be called with c.mu held.
+func (c *connection) setClients(ch chain) {
+	c.sshclient = ch.clients[len(ch.clients)-1]
+	c.bastionclients = ch.clients[:len(ch.clients)-1]
+	c.agentconns = ch.agentconns
+	c.sftpclient = nil
+	c.lost = false
+
+	go c.keepAlive(c.sshclient)
+}
+
+// keepAlive periodically sends keepalive requests to the host, marking the
+// connection as lost if the host stops responding or the connection is closed
+// by the remote side
+func (c *connection) keepAlive(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(c.opts.KeepAliveInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-done:
+			c.markLost(client)
+			return
+		case <-ticker.C:
+			if err := sendKeepAlive(client, c.opts.KeepAliveInterval); err != nil {
+				failures++
+			} else {
+				failures = 0
+			}
+
+			if failures >= keepAliveMaxFailures {
+				c.markLost(client)
+				return
+			}
+		}
+	}
+}
+
+// sendKeepAlive sends a single keepalive request, waiting for the reply
+// at most for the given timeout
+func sendKeepAlive(client *ssh.Client, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		// servers reply with failure to the unknown request, which still
+		// proves the connection is alive
+		_, _, err := client.SendRequest(keepAliveRequest, true, nil)
+		errCh <- err
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(timeout):
+		return errors.New("keepalive request timed out")
+	}
+}
+
+// markLost closes the given client, if it's still used by the connection,
+// so that the connection is re-dialed before it's used again
+func (c *connection) markLost(client *ssh.Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || c.sshclient != client {
+		return
+	}
+
+	_ = c.closeClients()
+	c.lost = true
+}
+
+func (c *connection) isLost() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lost
+}
+
+// reconnect re-dials the lost connection. Concurrent callers wait for the
+// connection re-dialed by the first one, instead of dialing on their own.
+func (c *connection) reconnect() error {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	c.mu.Lock()
+	lost := c.lost && !c.closed
+	c.mu.Unlock()
+
+	if !lost {
+		return nil
+	}
+
+	ch, err := c.connector.dial(c.opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reconnect to %s", c.opts.Hostname)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		ch.close()
+		return nil
+	}
+
+	c.setClients(ch)
+
+	return nil
+}
+
+// RetryOnLost runs the given operation, retrying it once after reconnecting
+// if it failed because the connection to the host was lost. The operation
+// must be idempotent.
+func (c *connection) RetryOnLost(fn func() error) error {
+	err := fn()
+	if err == nil || !c.checkLost(err) {
+		return err
+	}
+
+	if rerr := c.reconnect(); rerr != nil {
+		return errors.Wrap(rerr, err.Error())
+	}
+
+	return fn()
+}
+
+// checkLost determines whether the given error was caused by losing the
+// connection to the host. The keepalive request is sent to tell apart errors
+// of dead connections from other errors, before the keepalive loop notices.
+func (c *connection) checkLost(err error) bool {
+	if errors.Is(err, errConnectionLost) {
+		return true
+	}
+	// the command exited on the host, so the connection was alive
+	if _, ok := errors.Cause(err).(*ssh.ExitError); ok {
+		return false
+	}
+
+	c.mu.Lock()
+	client := c.sshclient
+	lost := c.lost
+	c.mu.Unlock()
+
+	if lost {
+		return true
+	}
+	if client == nil {
+		return false
+	}
+
+	if sendKeepAlive(client, c.opts.KeepAliveInterval) != nil {
+		c.markLost(client)
+		return true
+	}
+
+	return false
+}
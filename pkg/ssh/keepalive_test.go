@@ -0,0 +1,379 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is a minimal SSH server accepting any password (by default), running
+// commands from exec requests on the local machine, and forwarding TCP
+// connections. Subsystems, such as SFTP, are not supported.
+type testServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+
+	mu    sync.Mutex
+	conns []net.Conn
+	// accepted is the number of connections accepted in total
+	accepted int
+}
+
+func newTestServer(t *testing.T) *testServer {
+	return newTestServerWithConfig(t, &ssh.ServerConfig{
+		PasswordCallback: func(ssh.ConnMetadata, []byte) (*ssh.Permissions, error) {
+			return nil, nil
+		},
+	})
+}
+
+// newTestServerWithConfig creates a test server authenticating clients as
+// given in the server config
+func newTestServerWithConfig(t *testing.T, config *ssh.ServerConfig) *testServer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{listener: listener, config: config, hostKey: signer.PublicKey()}
+	go s.serve()
+
+	return s
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.accepted++
+		s.mu.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+func (s *testServer) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChan := range chans {
+		if newChan.ChannelType() == "direct-tcpip" {
+			go forward(newChan)
+			continue
+		}
+
+		ch, chReqs, err := newChan.Accept()
+		if err != nil {
+			continue
+		}
+
+		go func() {
+			for req := range chReqs {
+				_ = req.Reply(req.Type == "exec", nil)
+				if req.Type == "exec" {
+					go runCommand(ch, string(req.Payload[4:]))
+				}
+			}
+		}()
+	}
+}
+
+// forward connects the direct-tcpip channel to the requested address
+func forward(newChan ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChan.ExtraData(), &payload); err != nil {
+		_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	conn, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+	if err != nil {
+		_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := newChan.Accept()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(ch, conn)
+		ch.Close()
+	}()
+	_, _ = io.Copy(conn, ch)
+	conn.Close()
+}
+
+// runCommand runs the command, streaming its input and output over the
+// channel, and replies with the exit status
+func runCommand(ch ssh.Channel, cmd string) {
+	defer ch.Close()
+
+	command := exec.Command("/bin/sh", "-c", cmd)
+	command.Stdin = ch
+	command.Stdout = ch
+	command.Stderr = ch.Stderr()
+
+	exitCode := 0
+	if err := command.Run(); err != nil {
+		exitCode = 255
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	status := make([]byte, 4)
+	binary.BigEndian.PutUint32(status, uint32(exitCode))
+	_, _ = ch.SendRequest("exit-status", false, status)
+}
+
+// hostPort returns the address the test server listens on
+func (s *testServer) hostPort() (string, int) {
+	addr := s.listener.Addr().(*net.TCPAddr)
+
+	return addr.IP.String(), addr.Port
+}
+
+// acceptedConnections returns the number of connections accepted in total
+func (s *testServer) acceptedConnections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.accepted
+}
+
+// dropConnections abruptly closes all accepted connections
+func (s *testServer) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// connect connects to the test server, using the password if no other
+// credentials are given
+func (s *testServer) connect(t *testing.T, o Opts) Connection {
+	conn, err := s.dial(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func (s *testServer) dial(o Opts) (Connection, error) {
+	o.Username = "root"
+	if o.KeyFile == "" && o.AgentSocket == "" {
+		o.Password = "test"
+	}
+	o.Hostname, o.Port = s.hostPort()
+	o.Timeout = 5 * time.Second
+
+	return NewConnection(NewConnector(context.Background()), o)
+}
+
+func TestConnectionReconnect(t *testing.T) {
+	server := newTestServer(t)
+	defer server.listener.Close()
+
+	conn := server.connect(t, Opts{KeepAliveInterval: time.Hour})
+	defer conn.Close()
+
+	if _, _, _, err := conn.Exec("true"); err != nil {
+		t.Fatalf("expected command to succeed, but got %v", err)
+	}
+
+	server.dropConnections()
+
+	if _, _, _, err := conn.Exec("true"); err != nil {
+		t.Fatalf("expected command to succeed after reconnecting, but got %v", err)
+	}
+}
+
+func TestConnectionConcurrentReconnect(t *testing.T) {
+	server := newTestServer(t)
+	defer server.listener.Close()
+
+	conn := server.connect(t, Opts{KeepAliveInterval: time.Hour})
+	defer conn.Close()
+
+	server.dropConnections()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, _, err := conn.Exec("true")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected command to succeed after reconnecting, but got %v", err)
+		}
+	}
+
+	// the initial connection and a single reconnection
+	if accepted := server.acceptedConnections(); accepted != 2 {
+		t.Errorf("expected the connection to be re-dialed once, but the server accepted %d connections", accepted)
+	}
+}
+
+func TestExecIdempotent(t *testing.T) {
+	tests := []struct {
+		name string
+		// idempotent runs the command using ExecIdempotent instead of Exec
+		idempotent       bool
+		expectedErr      bool
+		expectedAccepted int
+	}{
+		{
+			name:             "idempotent command is retried",
+			idempotent:       true,
+			expectedAccepted: 2,
+		},
+		{
+			name:             "other command is not retried",
+			expectedErr:      true,
+			expectedAccepted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t)
+			defer server.listener.Close()
+
+			conn := server.connect(t, Opts{KeepAliveInterval: time.Hour})
+			defer conn.Close()
+
+			// the first run of the command blocks until the connection is
+			// dropped, the second one completes
+			marker := filepath.Join(t.TempDir(), "started")
+			cmd := fmt.Sprintf(`if [ -f %[1]s ]; then echo done; else touch %[1]s; sleep 10; fi`, marker)
+
+			type result struct {
+				stdout string
+				err    error
+			}
+			done := make(chan result, 1)
+			go func() {
+				run := conn.Exec
+				if tt.idempotent {
+					run = func(cmd string) (string, string, int, error) { return ExecIdempotent(conn, cmd) }
+				}
+				stdout, _, _, err := run(cmd)
+				done <- result{stdout: stdout, err: err}
+			}()
+
+			for {
+				if _, err := os.Stat(marker); err == nil {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			server.dropConnections()
+
+			var res result
+			select {
+			case res = <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("command was not interrupted by dropping the connection")
+			}
+
+			if tt.expectedErr {
+				if res.err == nil {
+					t.Fatal("expected the interrupted command to fail, but it succeeded")
+				}
+			} else if res.err != nil {
+				t.Fatalf("expected the command to succeed after reconnecting, but got %v", res.err)
+			} else if res.stdout != "done" {
+				t.Errorf("expected the command to be run again, but got output %q", res.stdout)
+			}
+
+			if accepted := server.acceptedConnections(); accepted != tt.expectedAccepted {
+				t.Errorf("expected %d connection(s), but the server accepted %d", tt.expectedAccepted, accepted)
+			}
+		})
+	}
+}
+
+func TestExecIdempotentExitCode(t *testing.T) {
+	server := newTestServer(t)
+	defer server.listener.Close()
+
+	conn := server.connect(t, Opts{KeepAliveInterval: time.Hour})
+	defer conn.Close()
+
+	_, _, exitCode, err := ExecIdempotent(conn, "exit 3")
+	if err == nil || exitCode != 3 {
+		t.Fatalf("expected the exit code 3, but got %d (%v)", exitCode, err)
+	}
+	if accepted := server.acceptedConnections(); accepted != 1 {
+		t.Errorf("expected the failed command not to be retried, but the server accepted %d connections", accepted)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	_ Connection = &localConnection{}
+	_ Tunneler   = &localConnection{}
+)
+
+// localConnection runs commands and accesses files on the machine KubeOne is
+// running on. Relative paths are resolved against the home directory, same as
+// over SSH.
+type localConnection struct {
+	connector *Connector
+	ctx       context.Context
+	cancel    context.CancelFunc
+	homeDir   string
+}
+
+// NewLocalConnection creates a Connection to the local machine
+func NewLocalConnection(connector *Connector) (Connection, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine home directory")
+	}
+
+	ctx, cancelFn := context.WithCancel(connector.ctx)
+
+	return &localConnection{
+		connector: connector,
+		ctx:       ctx,
+		cancel:    cancelFn,
+		homeDir:   home,
+	}, nil
+}
+
+func (c *localConnection) Exec(cmd string) (string, string, int, error) {
+	var stdoutBuf, stderrBuf strings.Builder
+
+	exitCode, err := c.Stream(cmd, &stdoutBuf, &stderrBuf)
+
+	return strings.TrimSpace(stdoutBuf.String()), stderrBuf.String(), exitCode, err
+}
+
+func (c *localConnection) Stream(cmd string, stdout io.Writer, stderr io.Writer) (int, error) {
+	return c.POpen(cmd, nil, stdout, stderr)
+}
+
+func (c *localConnection) POpen(cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+	command := exec.CommandContext(c.ctx, "/bin/bash", "-c", cmd)
+	command.Dir = c.homeDir
+	command.Stdin = stdin
+	command.Stdout = stdout
+	command.Stderr = stderr
+
+	exitCode := 0
+	if err := command.Run(); err != nil {
+		exitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+
+		// preserve original error
+		return exitCode, err
+	}
+
+	return exitCode, nil
+}
+
+func (c *localConnection) File(filename string, flags int) (io.ReadWriteCloser, error) {
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(c.homeDir, filename)
+	}
+
+	return os.OpenFile(filename, flags, 0644)
+}
+
+func (c *localConnection) TunnelTo(_ context.Context, network, addr string) (net.Conn, error) {
+	// the given context is ignored for the same reason as in
+	// connection.TunnelTo, the connection context is used instead
+	var d net.Dialer
+
+	netconn, err := d.DialContext(c.ctx, network, addr)
+	if err == nil {
+		go func() {
+			<-c.ctx.Done()
+			netconn.Close()
+		}()
+	}
+
+	return netconn, err
+}
+
+func (c *localConnection) Close() error {
+	c.cancel()
+	c.connector.forgetConnection(c)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalConnection(t *testing.T) {
+	conn, err := NewLocalConnection(NewConnector(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stdout, _, exitCode, err := conn.Exec("echo hello")
+	if err != nil || exitCode != 0 || stdout != "hello" {
+		t.Errorf("expected %q with exit code 0, but got %q with exit code %d (%v)", "hello", stdout, exitCode, err)
+	}
+
+	if _, _, exitCode, err = conn.Exec("exit 3"); err == nil || exitCode != 3 {
+		t.Errorf("expected exit code 3 and error, but got exit code %d (%v)", exitCode, err)
+	}
+
+	dir, err := ioutil.TempDir("", "kubeone-local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test")
+	f, err := conn.File(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write([]byte("content")); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if stdout, _, _, err = conn.Exec("cat " + filename); err != nil || stdout != "content" {
+		t.Errorf("expected %q, but got %q (%v)", "content", stdout, err)
+	}
+}
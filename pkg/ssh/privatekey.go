@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// PassphraseEnvVar is the name of the environment variable used to source
+// the passphrase for encrypted SSH private keys
+const PassphraseEnvVar = "KUBEONE_SSH_KEY_PASSPHRASE"
+
+// privateKeySigner parses the given private key, decrypting it if needed.
+// Parsed keys are cached by the Connector, so the passphrase is asked for only
+// once for all hosts sharing the same key.
+func (c *Connector) privateKeySigner(o authOpts) (ssh.Signer, error) {
+	c.signersLock.Lock()
+	defer c.signersLock.Unlock()
+
+	cacheKey := fmt.Sprintf("%x", sha256.Sum256([]byte(o.PrivateKey)))
+	if signer, ok := c.signers[cacheKey]; ok {
+		return signer, nil
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(o.PrivateKey))
+	if _, ok := err.(*ssh.PassphraseMissingError); ok {
+		var passphrase []byte
+
+		passphrase, err = privateKeyPassphrase(o)
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(o.PrivateKey), passphrase)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt the SSH key %q", o.KeyFile)
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "the SSH key %q could not be parsed", o.KeyFile)
+	}
+
+	c.signers[cacheKey] = signer
+
+	return signer, nil
+}
+
+// privateKeyPassphrase sources the passphrase for the encrypted private key from
+// the passphrase file, the environment, or asks for it if running in the terminal
+func privateKeyPassphrase(o authOpts) ([]byte, error) {
+	if len(o.PrivateKeyPassphraseFile) > 0 {
+		passphrase, err := ioutil.ReadFile(o.PrivateKeyPassphraseFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read SSH key passphrase file %q", o.PrivateKeyPassphraseFile)
+		}
+
+		return bytes.TrimRight(passphrase, "\r\n"), nil
+	}
+
+	if passphrase := os.Getenv(PassphraseEnvVar); len(passphrase) > 0 {
+		return []byte(passphrase), nil
+	}
+
+	return promptPassphrase(o.KeyFile)
+}
+
+// promptPassphrase asks for the passphrase of the SSH key if running in the
+// terminal. It's a variable, so it can be replaced in tests.
+var promptPassphrase = func(keyFile string) ([]byte, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.Errorf("the SSH key %q is encrypted, but no passphrase is provided (use sshPrivateKeyPassphraseFile, or the %s environment variable)", keyFile, PassphraseEnvVar)
+	}
+
+	fmt.Fprintf(os.Stderr, "Enter passphrase for the SSH key %q: ", keyFile)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read SSH key passphrase")
+	}
+
+	return passphrase, nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+const testPassphrase = "correct horse"
+
+// encryptedPEMKey generates the RSA key, encrypted in the legacy PEM format
+func encryptedPEMKey(t *testing.T, passphrase string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//nolint:staticcheck // the legacy PEM encryption is still used by the SSH keys
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte(passphrase), x509.PEMCipherAES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(block))
+}
+
+// encryptedOpenSSHKey generates the ed25519 key, encrypted in the OpenSSH
+// format by ssh-keygen
+func encryptedOpenSSHKey(t *testing.T, passphrase string) string {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not available")
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", passphrase, "-C", "", "-f", keyFile).CombinedOutput(); err != nil {
+		t.Fatalf("failed to generate the key: %v: %s", err, out)
+	}
+
+	content, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
+
+// plainKey generates the unencrypted ed25519 key
+func plainKey(t *testing.T) string {
+	_, priv := newTestSigner(t)
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	writeKeyFile(t, keyFile, priv)
+
+	content, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
+
+// stubPrompt replaces the passphrase prompt, answering with the passphrase,
+// or failing like without the terminal if it's empty. It returns the number
+// of times the passphrase was asked for.
+func stubPrompt(t *testing.T, passphrase string) *int {
+	prompted := 0
+	original := promptPassphrase
+	promptPassphrase = func(string) ([]byte, error) {
+		prompted++
+		if passphrase == "" {
+			return nil, errors.New("no terminal")
+		}
+
+		return []byte(passphrase), nil
+	}
+	t.Cleanup(func() { promptPassphrase = original })
+
+	return &prompted
+}
+
+func TestPrivateKeySigner(t *testing.T) {
+	tests := []struct {
+		name string
+		key  func(t *testing.T) string
+		// passphraseFile is the content of the passphrase file, which is not
+		// used if empty
+		passphraseFile   string
+		env              string
+		prompt           string
+		expectedPrompted int
+		expectedErr      string
+	}{
+		{
+			name: "unencrypted key",
+			key:  plainKey,
+		},
+		{
+			name:           "encrypted PEM key",
+			key:            func(t *testing.T) string { return encryptedPEMKey(t, testPassphrase) },
+			passphraseFile: testPassphrase + "\n",
+		},
+		{
+			name:           "encrypted OpenSSH key",
+			key:            func(t *testing.T) string { return encryptedOpenSSHKey(t, testPassphrase) },
+			passphraseFile: testPassphrase + "\n",
+		},
+		{
+			name:           "passphrase file is preferred to the environment",
+			key:            func(t *testing.T) string { return encryptedPEMKey(t, testPassphrase) },
+			passphraseFile: testPassphrase,
+			env:            "wrong",
+			prompt:         "wrong",
+		},
+		{
+			name:   "environment is preferred to the prompt",
+			key:    func(t *testing.T) string { return encryptedPEMKey(t, testPassphrase) },
+			env:    testPassphrase,
+			prompt: "wrong",
+		},
+		{
+			name:             "passphrase is asked for",
+			key:              func(t *testing.T) string { return encryptedOpenSSHKey(t, testPassphrase) },
+			prompt:           testPassphrase,
+			expectedPrompted: 1,
+		},
+		{
+			name:             "no passphrase",
+			key:              func(t *testing.T) string { return encryptedPEMKey(t, testPassphrase) },
+			expectedPrompted: 1,
+			expectedErr:      "no terminal",
+		},
+		{
+			name:           "wrong passphrase",
+			key:            func(t *testing.T) string { return encryptedPEMKey(t, testPassphrase) },
+			passphraseFile: "wrong",
+			expectedErr:    `failed to decrypt the SSH key "id_rsa"`,
+		},
+		{
+			name:           "wrong OpenSSH key passphrase",
+			key:            func(t *testing.T) string { return encryptedOpenSSHKey(t, testPassphrase) },
+			passphraseFile: "wrong",
+			expectedErr:    `failed to decrypt the SSH key "id_rsa"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := authOpts{KeyFile: "id_rsa", PrivateKey: tt.key(t)}
+			if tt.passphraseFile != "" {
+				o.PrivateKeyPassphraseFile = filepath.Join(t.TempDir(), "passphrase")
+				if err := ioutil.WriteFile(o.PrivateKeyPassphraseFile, []byte(tt.passphraseFile), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			os.Setenv(PassphraseEnvVar, tt.env)
+			defer os.Unsetenv(PassphraseEnvVar)
+			prompted := stubPrompt(t, tt.prompt)
+
+			signer, err := NewConnector(context.Background()).privateKeySigner(o)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Fatalf("expected error %q, but got %v", tt.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if signer == nil {
+				t.Fatal("expected signer, but got nil")
+			}
+
+			if *prompted != tt.expectedPrompted {
+				t.Errorf("expected the passphrase to be asked for %d time(s), but it was asked for %d time(s)", tt.expectedPrompted, *prompted)
+			}
+		})
+	}
+}
+
+func TestPrivateKeySignerCache(t *testing.T) {
+	prompted := stubPrompt(t, testPassphrase)
+	connector := NewConnector(context.Background())
+
+	key := encryptedPEMKey(t, testPassphrase)
+	otherKey := encryptedPEMKey(t, testPassphrase)
+
+	first, err := connector.privateKeySigner(authOpts{KeyFile: "id_rsa", PrivateKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same key read from another file is parsed only once
+	second, err := connector.privateKeySigner(authOpts{KeyFile: "copy/id_rsa", PrivateKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected the cached signer for the same key")
+	}
+	if *prompted != 1 {
+		t.Errorf("expected the passphrase to be asked for once, but it was asked for %d time(s)", *prompted)
+	}
+	if _, ok := connector.signers[fmt.Sprintf("%x", sha256.Sum256([]byte(key)))]; !ok {
+		t.Error("expected the signer to be cached by the sha256 of the key")
+	}
+
+	other, err := connector.privateKeySigner(authOpts{KeyFile: "id_rsa", PrivateKey: otherKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Error("expected another signer for another key")
+	}
+	if *prompted != 2 {
+		t.Errorf("expected the passphrase to be asked for the other key, but it was asked for %d time(s)", *prompted)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ssh
+
+import (
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+var (
+	_ Shell = &connection{}
+	_ Shell = &localConnection{}
+)
+
+// Shell interface opens the interactive login shell on the host
+type Shell interface {
+	// Shell runs the shell until it exits. If stdin is a terminal, it's
+	// switched to the raw mode and the pseudo-terminal is allocated for the
+	// shell.
+	Shell(stdin *os.File, stdout io.Writer, stderr io.Writer) (exitCode int, err error)
+}
+
+func (c *connection) Shell(stdin *os.File, stdout io.Writer, stderr io.Writer) (int, error) {
+	sess, err := c.session()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get SSH session")
+	}
+	defer sess.Close()
+
+	fd := int(stdin.Fd())
+	if term.IsTerminal(fd) {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get terminal size")
+		}
+
+		termType := os.Getenv("TERM")
+		if termType == "" {
+			termType = "xterm"
+		}
+
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+		if err = sess.RequestPty(termType, height, width, modes); err != nil {
+			return 0, errors.Wrap(err, "failed to request pseudo-terminal")
+		}
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to switch terminal to raw mode")
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+
+		stop := watchTerminalSize(fd, func(width, height int) {
+			_ = sess.WindowChange(height, width)
+		})
+		defer stop()
+	}
+
+	sess.Stdin = stdin
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+
+	if err = sess.Shell(); err != nil {
+		return 0, errors.Wrap(err, "failed to start shell")
+	}
+
+	if err = sess.Wait(); err != nil {
+		if exitErr, ok := err.(*ssh.ExitError); ok {
+			// exiting the shell with non-zero status is not an error
+			return exitErr.ExitStatus(), nil
+		}
+
+		return -1, err
+	}
+
+	return 0, nil
+}
+
+func (c *localConnection) Shell(stdin *os.File, stdout io.Writer, stderr io.Writer) (int, error) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+
+	command := exec.CommandContext(c.ctx, shell, "-l")
+	command.Dir = c.homeDir
+	command.Stdin = stdin
+	command.Stdout = stdout
+	command.Stderr = stderr
+
+	if err := command.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+
+		return -1, err
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,480 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sshconfig implements resolving of connection settings from the
+// OpenSSH client configuration file (ssh_config(5)).
+//
+// Only a subset of the ssh_config keywords is supported: Host, Match, Include,
+// HostName, User, Port, IdentityFile, CertificateFile and ProxyJump. Match criteria other than
+// all, host, originalhost, user and localuser (e.g. exec) never match.
+package sshconfig
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// maxIncludeDepth limits recursion of the Include keyword
+const maxIncludeDepth = 16
+
+// Config is the parsed OpenSSH client configuration
+type Config struct {
+	path  string
+	home  string
+	files map[string][]directive
+}
+
+// Host holds the connection settings resolved for a single host
+type Host struct {
+	// HostName is the real host name to connect to. It's the given alias if
+	// the HostName keyword is not set.
+	HostName string
+	// User is the login name, empty if not set
+	User string
+	// Port is the SSH port, 0 if not set
+	Port int
+	// IdentityFiles are paths to the private keys, in the order of preference
+	IdentityFiles []string
+	// CertificateFiles are paths to the user certificates, in the order of
+	// preference
+	CertificateFiles []string
+	// ProxyJump is the chain of jump hosts, empty if not set
+	ProxyJump []Jump
+}
+
+// Jump is a single jump host as given in the ProxyJump keyword
+type Jump struct {
+	User string
+	Host string
+	Port int
+}
+
+type directive struct {
+	keyword string
+	args    []string
+	line    int
+}
+
+// Load parses the OpenSSH client configuration file on the given path.
+// Leading "~" in the path is expanded to the home directory.
+func Load(filename string) (*Config, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to determine home directory")
+	}
+
+	c := &Config{
+		home:  home,
+		files: map[string][]directive{},
+	}
+	c.path = c.expandHome(filename)
+
+	if _, err := c.parse(c.path); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Resolve resolves the connection settings for the given host alias. The user
+// is the explicitly configured login name, if any. It's used for evaluation of
+// the Match user criterion and for the %r token.
+func (c *Config) Resolve(alias, username string) (Host, error) {
+	r := &resolver{
+		config:   c,
+		alias:    alias,
+		user:     username,
+		hostname: alias,
+	}
+
+	localUser := ""
+	if u, err := user.Current(); err == nil {
+		localUser = u.Username
+	}
+	r.localUser = localUser
+
+	if err := r.evaluate(c.path, 0); err != nil {
+		return Host{}, err
+	}
+
+	host := Host{
+		HostName: r.hostname,
+		User:     r.user,
+	}
+
+	if port, ok := r.values["port"]; ok {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return Host{}, errors.Wrapf(err, "invalid port %q for host %q", port, alias)
+		}
+		host.Port = p
+	}
+
+	if r.proxyJump != "" && !strings.EqualFold(r.proxyJump, "none") {
+		jumps, err := ParseProxyJump(r.proxyJump)
+		if err != nil {
+			return Host{}, errors.Wrapf(err, "invalid ProxyJump for host %q", alias)
+		}
+		host.ProxyJump = jumps
+	}
+
+	for _, identity := range r.identityFiles {
+		if strings.EqualFold(identity, "none") {
+			continue
+		}
+		host.IdentityFiles = append(host.IdentityFiles, c.expandHome(r.expandTokens(identity)))
+	}
+
+	for _, certificate := range r.certificateFiles {
+		host.CertificateFiles = append(host.CertificateFiles, c.expandHome(r.expandTokens(certificate)))
+	}
+
+	return host, nil
+}
+
+// ParseProxyJump parses the comma separated list of jump hosts in the
+// [user@]host[:port] or ssh://[user@]host[:port] format
+func ParseProxyJump(value string) ([]Jump, error) {
+	jumps := []Jump{}
+
+	for _, hop := range strings.Split(value, ",") {
+		hop = strings.TrimPrefix(strings.TrimSpace(hop), "ssh://")
+		if hop == "" {
+			return nil, errors.Errorf("empty jump host in %q", value)
+		}
+
+		jump := Jump{}
+		if i := strings.LastIndex(hop, "@"); i >= 0 {
+			jump.User = hop[:i]
+			hop = hop[i+1:]
+		}
+
+		jump.Host = hop
+		if host, port, err := net.SplitHostPort(hop); err == nil {
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				return nil, errors.Errorf("invalid port %q in %q", port, value)
+			}
+			jump.Host = host
+			jump.Port = p
+		} else {
+			jump.Host = strings.TrimSuffix(strings.TrimPrefix(hop, "["), "]")
+		}
+
+		if jump.Host == "" {
+			return nil, errors.Errorf("empty jump host in %q", value)
+		}
+
+		jumps = append(jumps, jump)
+	}
+
+	return jumps, nil
+}
+
+func (c *Config) expandHome(p string) string {
+	if p == "~" {
+		return c.home
+	}
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(c.home, p[2:])
+	}
+
+	return p
+}
+
+// parse reads and caches directives of the given file
+func (c *Config) parse(filename string) ([]directive, error) {
+	if directives, ok := c.files[filename]; ok {
+		return directives, nil
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read ssh config file %q", filename)
+	}
+
+	directives := []directive{}
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, rest := splitKeyword(line)
+		args, err := splitArgs(rest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s:%d", filename, lineNo)
+		}
+
+		directives = append(directives, directive{
+			keyword: strings.ToLower(keyword),
+			args:    args,
+			line:    lineNo,
+		})
+	}
+
+	c.files[filename] = directives
+
+	return directives, nil
+}
+
+// splitKeyword splits the line into the keyword and the arguments, which are
+// separated by whitespace and/or a single "="
+func splitKeyword(line string) (string, string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, ""
+	}
+
+	keyword := line[:i]
+	rest := strings.TrimLeft(line[i:], " \t")
+	if strings.HasPrefix(rest, "=") {
+		rest = strings.TrimLeft(rest[1:], " \t")
+	}
+
+	return keyword, rest
+}
+
+func splitArgs(s string) ([]string, error) {
+	args := []string{}
+
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" || strings.HasPrefix(s, "#") {
+			return args, nil
+		}
+
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, errors.New("unterminated quoted argument")
+			}
+			args = append(args, s[1:end+1])
+			s = s[end+2:]
+			continue
+		}
+
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			return append(args, s), nil
+		}
+		args = append(args, s[:end])
+		s = s[end:]
+	}
+}
+
+type resolver struct {
+	config    *Config
+	alias     string
+	hostname  string
+	user      string
+	localUser string
+
+	hostnameSet      bool
+	proxyJump        string
+	identityFiles    []string
+	certificateFiles []string
+	values           map[string]string
+}
+
+func (r *resolver) evaluate(filename string, depth int) error {
+	if depth > maxIncludeDepth {
+		return errors.Errorf("too many nested includes in %q", filename)
+	}
+
+	directives, err := r.config.parse(filename)
+	if err != nil {
+		return err
+	}
+
+	if r.values == nil {
+		r.values = map[string]string{}
+	}
+
+	active := true
+	for _, d := range directives {
+		switch d.keyword {
+		case "host":
+			active = r.matchHost(d.args)
+			continue
+		case "match":
+			active, err = r.matchCriteria(d.args)
+			if err != nil {
+				return errors.Wrapf(err, "%s:%d", filename, d.line)
+			}
+			continue
+		}
+
+		if !active || len(d.args) == 0 {
+			continue
+		}
+
+		switch d.keyword {
+		case "include":
+			for _, pattern := range d.args {
+				if err := r.include(pattern, depth); err != nil {
+					return err
+				}
+			}
+		case "hostname":
+			if !r.hostnameSet {
+				r.hostnameSet = true
+				r.hostname = r.expandTokens(d.args[0])
+			}
+		case "user":
+			if r.user == "" {
+				r.user = d.args[0]
+			}
+		case "proxyjump":
+			if r.proxyJump == "" {
+				r.proxyJump = strings.Join(d.args, ",")
+			}
+		case "identityfile":
+			r.identityFiles = append(r.identityFiles, d.args[0])
+		case "certificatefile":
+			r.certificateFiles = append(r.certificateFiles, d.args[0])
+		default:
+			if _, ok := r.values[d.keyword]; !ok {
+				r.values[d.keyword] = d.args[0]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *resolver) include(pattern string, depth int) error {
+	pattern = r.config.expandHome(pattern)
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(r.config.home, ".ssh", pattern)
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return errors.Wrapf(err, "invalid Include pattern %q", pattern)
+	}
+
+	for _, match := range matches {
+		if err := r.evaluate(match, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matchHost matches the alias against the list of patterns. Negated patterns
+// take precedence over the other patterns.
+func (r *resolver) matchHost(patterns []string) bool {
+	return matchPatternList(r.alias, patterns)
+}
+
+func (r *resolver) matchCriteria(args []string) (bool, error) {
+	matched := true
+
+	for i := 0; i < len(args); i++ {
+		criterion := strings.ToLower(args[i])
+		negate := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+
+		var result bool
+		switch criterion {
+		case "all":
+			result = true
+		case "canonical", "final":
+			result = false
+		default:
+			if i+1 >= len(args) {
+				return false, errors.Errorf("missing argument for Match %s", criterion)
+			}
+			i++
+			patterns := strings.Split(args[i], ",")
+
+			switch criterion {
+			case "host":
+				result = matchPatternList(r.hostname, patterns)
+			case "originalhost":
+				result = matchPatternList(r.alias, patterns)
+			case "user":
+				result = matchPatternList(r.user, patterns)
+			case "localuser":
+				result = matchPatternList(r.localUser, patterns)
+			default:
+				// exec and other criteria are not supported
+				result = false
+			}
+		}
+
+		if negate {
+			result = !result
+		}
+		matched = matched && result
+	}
+
+	return matched, nil
+}
+
+func matchPatternList(value string, patterns []string) bool {
+	matched := false
+
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		// path.Match implements the same * and ? wildcards as ssh_config,
+		// and "/" is not a valid character in host names anyway
+		ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+		if err != nil || !ok {
+			continue
+		}
+
+		if negate {
+			return false
+		}
+		matched = true
+	}
+
+	return matched
+}
+
+// expandTokens expands the subset of tokens supported by HostName, IdentityFile
+// and CertificateFile
+func (r *resolver) expandTokens(value string) string {
+	replacer := strings.NewReplacer(
+		"%%", "%",
+		"%d", r.config.home,
+		"%h", r.hostname,
+		"%n", r.alias,
+		"%r", r.user,
+		"%u", r.localUser,
+	)
+
+	return replacer.Replace(value)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testConfig = `
+# bastion of the private network
+Host bastion
+    HostName 203.0.113.10
+    User jump
+    Port 2222
+
+Host cp-* !cp-legacy
+    User ubuntu
+    ProxyJump bastion
+    IdentityFile "/keys/%h.pem"
+    CertificateFile /keys/%h-cert.pub
+
+Host cp-1
+    HostName=10.0.0.11
+
+Match originalhost cp-2 user admin
+    HostName 10.0.0.12
+
+Host *
+    User root
+    Port 22
+    IdentityFile /keys/default.pem
+`
+
+func TestResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-ssh-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config")
+	if err = ioutil.WriteFile(filename, []byte(testConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		alias    string
+		user     string
+		expected Host
+	}{
+		{
+			name:  "host with jump host",
+			alias: "cp-1",
+			expected: Host{
+				HostName:         "10.0.0.11",
+				User:             "ubuntu",
+				Port:             22,
+				IdentityFiles:    []string{"/keys/10.0.0.11.pem", "/keys/default.pem"},
+				CertificateFiles: []string{"/keys/10.0.0.11-cert.pub"},
+				ProxyJump:        []Jump{{Host: "bastion"}},
+			},
+		},
+		{
+			name:  "negated pattern",
+			alias: "cp-legacy",
+			expected: Host{
+				HostName:      "cp-legacy",
+				User:          "root",
+				Port:          22,
+				IdentityFiles: []string{"/keys/default.pem"},
+			},
+		},
+		{
+			name:  "match on explicit user",
+			alias: "cp-2",
+			user:  "admin",
+			expected: Host{
+				HostName:         "10.0.0.12",
+				User:             "admin",
+				Port:             22,
+				IdentityFiles:    []string{"/keys/10.0.0.12.pem", "/keys/default.pem"},
+				CertificateFiles: []string{"/keys/10.0.0.12-cert.pub"},
+				ProxyJump:        []Jump{{Host: "bastion"}},
+			},
+		},
+		{
+			name:  "jump host",
+			alias: "bastion",
+			expected: Host{
+				HostName:      "203.0.113.10",
+				User:          "jump",
+				Port:          2222,
+				IdentityFiles: []string{"/keys/default.pem"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, err := cfg.Resolve(tc.alias, tc.user)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(host, tc.expected) {
+				t.Errorf("expected %+v, but got %+v", tc.expected, host)
+			}
+		})
+	}
+}
+
+func TestParseProxyJump(t *testing.T) {
+	jumps, err := ParseProxyJump("admin@bastion:2222,ssh://[2001:db8::1]:22,[2001:db8::2]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Jump{
+		{User: "admin", Host: "bastion", Port: 2222},
+		{Host: "2001:db8::1", Port: 22},
+		{Host: "2001:db8::2"},
+	}
+	if !reflect.DeepEqual(jumps, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, jumps)
+	}
+}
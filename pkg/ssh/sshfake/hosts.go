@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshfake
+
+import (
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+)
+
+var osReleases = map[kubeoneapi.OperatingSystemName]string{
+	kubeoneapi.OperatingSystemNameUbuntu: `NAME="Ubuntu"
+VERSION="20.04.2 LTS (Focal Fossa)"
+ID=ubuntu
+ID_LIKE=debian
+VERSION_ID="20.04"
+`,
+	kubeoneapi.OperatingSystemNameCentOS: `NAME="CentOS Linux"
+VERSION="7 (Core)"
+ID="centos"
+ID_LIKE="rhel fedora"
+VERSION_ID="7"
+`,
+	kubeoneapi.OperatingSystemNameFlatcar: `NAME="Flatcar Container Linux by Kinvolk"
+ID=flatcar
+ID_LIKE=coreos
+VERSION=2765.2.2
+VERSION_ID=2765.2.2
+`,
+}
+
+// NewOSHost creates a simulated host running the given operating system,
+// with the /etc/os-release file and the hostname command in place
+func NewOSHost(osName kubeoneapi.OperatingSystemName, address, hostname string) *Host {
+	h := NewHost(address)
+	h.WriteFile("/etc/os-release", []byte(osReleases[osName]))
+	h.On(`hostname -f|^hostname$`, Response{Stdout: hostname + "\n"})
+
+	return h
+}
+
+// NewUbuntuHost creates a simulated Ubuntu host
+func NewUbuntuHost(address, hostname string) *Host {
+	return NewOSHost(kubeoneapi.OperatingSystemNameUbuntu, address, hostname)
+}
+
+// NewCentOSHost creates a simulated CentOS host
+func NewCentOSHost(address, hostname string) *Host {
+	return NewOSHost(kubeoneapi.OperatingSystemNameCentOS, address, hostname)
+}
+
+// NewFlatcarHost creates a simulated Flatcar host
+func NewFlatcarHost(address, hostname string) *Host {
+	return NewOSHost(kubeoneapi.OperatingSystemNameFlatcar, address, hostname)
+}
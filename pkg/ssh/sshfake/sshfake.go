@@ -0,0 +1,363 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sshfake implements simulated hosts, used to test tasks without
+// real machines.
+//
+// Every simulated host has a virtual filesystem, backing the File method of
+// the connection, and a list of scripted responses, keyed by regular
+// expressions matched against commands run via Exec, Stream and POpen. Commands
+// not matching any response succeed with no output. All commands are recorded
+// in the host history.
+package sshfake
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh"
+)
+
+// Response is a scripted response to a command
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// Fn, if set, is called to run the command instead of returning the
+	// static response, e.g. to modify the filesystem of the host. stdin is
+	// the input passed to the command, if any.
+	Fn func(h *Host, cmd string, stdin []byte) Response
+}
+
+type scriptedResponse struct {
+	pattern  *regexp.Regexp
+	response Response
+}
+
+// Host is a simulated host
+type Host struct {
+	// Address is the public address of the host
+	Address string
+
+	mu        sync.Mutex
+	files     map[string][]byte
+	responses []scriptedResponse
+	history   []string
+}
+
+var (
+	// listFilesCmd matches the command listing files in the directory, as
+	// used by configupload.Configuration.Download
+	listFilesCmd = regexp.MustCompile(`^cd -- "(.+)" && find \* -type f$`)
+	// extractTarCmd matches the command extracting the tar archive to the
+	// directory, as used by configupload.Configuration.UploadTo
+	extractTarCmd = regexp.MustCompile(`^mkdir -p -- "(.+)" && tar .*-f -$`)
+)
+
+// NewHost creates a simulated host with the given public address. Listing of
+// files in the directory and extracting tar archives are simulated using the
+// virtual filesystem.
+func NewHost(address string) *Host {
+	h := &Host{
+		Address: address,
+		files:   map[string][]byte{},
+	}
+
+	h.On(listFilesCmd.String(), Response{Fn: listFiles})
+	h.On(extractTarCmd.String(), Response{Fn: extractTar})
+
+	return h
+}
+
+func listFiles(h *Host, cmd string, _ []byte) Response {
+	dir := strings.TrimSuffix(listFilesCmd.FindStringSubmatch(cmd)[1], "/") + "/"
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	files := []string{}
+	for name := range h.files {
+		if strings.HasPrefix(name, dir) {
+			files = append(files, strings.TrimPrefix(name, dir))
+		}
+	}
+	if len(files) == 0 {
+		return Response{Stderr: "No such file or directory", ExitCode: 1}
+	}
+	sort.Strings(files)
+
+	return Response{Stdout: strings.Join(files, "\n") + "\n"}
+}
+
+func extractTar(h *Host, cmd string, stdin []byte) Response {
+	dir := extractTarCmd.FindStringSubmatch(cmd)[1]
+
+	tr := tar.NewReader(bytes.NewReader(stdin))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Response{Stderr: err.Error(), ExitCode: 2}
+		}
+
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return Response{Stderr: err.Error(), ExitCode: 2}
+		}
+		h.WriteFile(path.Join(dir, hdr.Name), content)
+	}
+
+	return Response{}
+}
+
+// On scripts the response for commands matching the given regular
+// expression. Responses added later take precedence over the earlier ones.
+func (h *Host) On(pattern string, response Response) *Host {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.responses = append([]scriptedResponse{{
+		pattern:  regexp.MustCompile(pattern),
+		response: response,
+	}}, h.responses...)
+
+	return h
+}
+
+// WriteFile writes the file to the virtual filesystem of the host
+func (h *Host) WriteFile(filename string, content []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.files[filename] = append([]byte{}, content...)
+}
+
+// ReadFile reads the file from the virtual filesystem of the host
+func (h *Host) ReadFile(filename string) ([]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	content, ok := h.files[filename]
+
+	return append([]byte{}, content...), ok
+}
+
+// RemoveFile removes the file from the virtual filesystem of the host
+func (h *Host) RemoveFile(filename string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.files, filename)
+}
+
+// History returns all commands run on the host, in order
+func (h *Host) History() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]string{}, h.history...)
+}
+
+// Ran returns whether a command matching the given regular expression was
+// run on the host
+func (h *Host) Ran(pattern string) bool {
+	re := regexp.MustCompile(pattern)
+	for _, cmd := range h.History() {
+		if re.MatchString(cmd) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Host) run(cmd string, stdin []byte) Response {
+	h.mu.Lock()
+	h.history = append(h.history, cmd)
+
+	var response *Response
+	for i := range h.responses {
+		if h.responses[i].pattern.MatchString(cmd) {
+			response = &h.responses[i].response
+			break
+		}
+	}
+	h.mu.Unlock()
+
+	if response == nil {
+		return Response{}
+	}
+
+	if response.Fn != nil {
+		return response.Fn(h, cmd, stdin)
+	}
+
+	return *response
+}
+
+// NewConnector creates an ssh.Connector connecting to the given simulated
+// hosts, matched by the public address
+func NewConnector(ctx context.Context, hosts ...*Host) *ssh.Connector {
+	byAddress := map[string]*Host{}
+	for _, h := range hosts {
+		byAddress[h.Address] = h
+	}
+
+	return ssh.NewConnectorWithDialer(ctx, func(host kubeoneapi.HostConfig) (ssh.Connection, error) {
+		h, ok := byAddress[host.PublicAddress]
+		if !ok {
+			return nil, errors.Errorf("no simulated host with address %q", host.PublicAddress)
+		}
+
+		return &Connection{Host: h}, nil
+	})
+}
+
+var (
+	_ ssh.Connection = &Connection{}
+	_ ssh.Tunneler   = &Connection{}
+)
+
+// Connection is a connection to the simulated host
+type Connection struct {
+	Host *Host
+}
+
+func (c *Connection) Exec(cmd string) (string, string, int, error) {
+	var stdout, stderr strings.Builder
+
+	exitCode, err := c.Stream(cmd, &stdout, &stderr)
+
+	return strings.TrimSpace(stdout.String()), stderr.String(), exitCode, err
+}
+
+func (c *Connection) Stream(cmd string, stdout io.Writer, stderr io.Writer) (int, error) {
+	return c.POpen(cmd, nil, stdout, stderr)
+}
+
+func (c *Connection) POpen(cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (int, error) {
+	var input []byte
+	if stdin != nil {
+		var err error
+		if input, err = ioutil.ReadAll(stdin); err != nil {
+			return -1, err
+		}
+	}
+
+	response := c.Host.run(cmd, input)
+
+	if _, err := io.WriteString(stdout, response.Stdout); err != nil {
+		return -1, err
+	}
+	if _, err := io.WriteString(stderr, response.Stderr); err != nil {
+		return -1, err
+	}
+
+	if response.ExitCode != 0 {
+		return response.ExitCode, errors.Errorf("Process exited with status %d", response.ExitCode)
+	}
+
+	return 0, nil
+}
+
+func (c *Connection) File(filename string, flags int) (io.ReadWriteCloser, error) {
+	content, ok := c.Host.ReadFile(filename)
+	if !ok {
+		if flags&os.O_CREATE == 0 {
+			return nil, errors.Wrapf(os.ErrNotExist, "open %s", filename)
+		}
+		content = nil
+	}
+
+	if flags&os.O_TRUNC != 0 {
+		content = nil
+	}
+
+	f := &file{
+		host:     c.Host,
+		name:     filename,
+		writable: flags&(os.O_WRONLY|os.O_RDWR) != 0,
+	}
+	f.buf.Write(content)
+	if flags&os.O_APPEND == 0 && f.writable {
+		// writes overwrite the content from the beginning
+		f.buf.Reset()
+		f.rest = content
+	}
+
+	return f, nil
+}
+
+// TunnelTo is not supported by simulated hosts
+func (c *Connection) TunnelTo(_ context.Context, network, addr string) (net.Conn, error) {
+	return nil, errors.Errorf("tunneling to %s/%s is not supported by simulated host %q", network, addr, c.Host.Address)
+}
+
+func (c *Connection) Close() error {
+	return nil
+}
+
+// file is an open file on the virtual filesystem, written back on Close
+type file struct {
+	host     *Host
+	name     string
+	writable bool
+	buf      bytes.Buffer
+	// rest is the original content not yet overwritten by writes
+	rest []byte
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	return f.buf.Read(p)
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	if !f.writable {
+		return 0, errors.Errorf("file %s is not opened for writing", f.name)
+	}
+
+	n, err := f.buf.Write(p)
+	if len(f.rest) > n {
+		f.rest = f.rest[n:]
+	} else {
+		f.rest = nil
+	}
+
+	return n, err
+}
+
+func (f *file) Close() error {
+	if f.writable {
+		f.host.WriteFile(f.name, append(f.buf.Bytes(), f.rest...))
+	}
+
+	return nil
+}
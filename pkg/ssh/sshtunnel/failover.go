@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshtunnel
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+
+	"k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh"
+)
+
+// FailoverDialer dials connections tunneled through one of the hosts. If the
+// tunnel through the current host fails, the other hosts are tried in turn.
+type FailoverDialer struct {
+	connector *ssh.Connector
+	hosts     []kubeone.HostConfig
+	logger    logrus.FieldLogger
+
+	mu      sync.Mutex
+	current int
+}
+
+// NewFailoverDialer creates a FailoverDialer tunneling through the given
+// hosts, starting with a random one
+func NewFailoverDialer(connector *ssh.Connector, hosts []kubeone.HostConfig, logger logrus.FieldLogger) (*FailoverDialer, error) {
+	if len(hosts) == 0 {
+		return nil, errors.New("no hosts to tunnel through")
+	}
+
+	return &FailoverDialer{
+		connector: connector,
+		hosts:     hosts,
+		logger:    logger,
+		//nolint:gosec
+		// G404: Use of weak random number generator (math/rand instead of crypto/rand) (gosec)
+		current: rand.Intn(len(hosts)),
+	}, nil
+}
+
+// Connect establishes the tunnel through the current host, failing over to
+// the other hosts if connecting fails
+func (d *FailoverDialer) Connect() error {
+	return d.failover(func(ssh.Tunneler) error { return nil })
+}
+
+// DialContext dials the address tunneled through the current host. Failing
+// to reach the address from the host, e.g. because the connection is refused,
+// is not considered a failure of the tunnel, as the other hosts would fail the
+// same way.
+func (d *FailoverDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var conn net.Conn
+
+	err := d.failover(func(tunn ssh.Tunneler) error {
+		var err error
+		conn, err = tunn.TunnelTo(ctx, network, addr)
+
+		return err
+	})
+
+	return conn, err
+}
+
+// Host returns the host currently tunneled through
+func (d *FailoverDialer) Host() kubeone.HostConfig {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.hosts[d.current]
+}
+
+func (d *FailoverDialer) failover(fn func(ssh.Tunneler) error) error {
+	d.mu.Lock()
+	start := d.current
+	d.mu.Unlock()
+
+	var lastErr error
+	for i := range d.hosts {
+		idx := (start + i) % len(d.hosts)
+		host := d.hosts[idx]
+
+		if lastErr != nil {
+			d.logger.Warnf("Failing over to %s: %v", host.PublicAddress, lastErr)
+		}
+
+		tunn, err := d.connector.Tunnel(host)
+		if err == nil {
+			err = fn(tunn)
+
+			var chanErr *gossh.OpenChannelError
+			if errors.As(err, &chanErr) {
+				return err
+			}
+			// the connection is shared with the other tunnels and sessions
+			// to the host, so it's not closed here. Dead connections are
+			// detected and marked lost by the connection itself, and
+			// re-dialed when the host is tried again.
+		}
+
+		if err == nil {
+			d.mu.Lock()
+			d.current = idx
+			d.mu.Unlock()
+
+			return nil
+		}
+
+		lastErr = errors.Wrapf(err, "tunnel through %s failed", host.PublicAddress)
+	}
+
+	return lastErr
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshtunnel
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/ssh"
+)
+
+type fakeTunnel struct {
+	ssh.Connection
+	err    error
+	closed bool
+}
+
+func (t *fakeTunnel) TunnelTo(context.Context, string, string) (net.Conn, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	client, server := net.Pipe()
+	server.Close()
+
+	return client, nil
+}
+
+func (t *fakeTunnel) Close() error {
+	t.closed = true
+	return nil
+}
+
+func TestFailoverDialerKeepsConnections(t *testing.T) {
+	tunnels := map[int]*fakeTunnel{
+		0: {err: errors.New("i/o timeout")},
+		1: {err: errors.New("i/o timeout")},
+		2: {},
+	}
+
+	connector := ssh.NewConnectorWithDialer(context.Background(), func(host kubeone.HostConfig) (ssh.Connection, error) {
+		return tunnels[host.ID], nil
+	})
+
+	hosts := []kubeone.HostConfig{{ID: 0}, {ID: 1}, {ID: 2}}
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+
+	dialer, err := NewFailoverDialer(connector, hosts, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dialer.current = 0
+
+	conn, err := dialer.DialContext(context.Background(), "tcp", "127.0.0.1:2379")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.Close()
+
+	if host := dialer.Host(); host.ID != 2 {
+		t.Errorf("expected to fail over to host 2, got %d", host.ID)
+	}
+	for id, tunn := range tunnels {
+		if tunn.closed {
+			t.Errorf("expected the shared connection to host %d to be kept open", id)
+		}
+	}
+}
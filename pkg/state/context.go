@@ -68,6 +68,9 @@ type State struct {
 	CredentialsFilePath       string
 	ManifestFilePath          string
 	PauseImage                string
+	// Secrets are the values interpolated into the manifest fields holding
+	// credentials, redacted from the logs and the support bundles
+	Secrets []string
 }
 
 func (s *State) KubeadmVerboseFlag() string {
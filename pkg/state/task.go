@@ -44,10 +44,12 @@ func (s *State) runTask(node *kubeoneapi.HostConfig, task NodeTask) error {
 	}
 
 	s.Runner = &runner.Runner{
-		Conn:    conn,
-		Verbose: s.Verbose,
-		OS:      node.OperatingSystem,
-		Prefix:  fmt.Sprintf("[%s] ", node.PublicAddress),
+		Conn:                conn,
+		Verbose:             s.Verbose,
+		OS:                  node.OperatingSystem,
+		Prefix:              fmt.Sprintf("[%s] ", node.PublicAddress),
+		PrivilegeEscalation: node.PrivilegeEscalation,
+		SudoPasswordFile:    node.SudoPasswordFile,
 	}
 
 	return task(s, node, conn)
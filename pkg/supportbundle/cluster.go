@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8c.io/kubeone/pkg/clusterstatus/etcdstatus"
+	"k8c.io/kubeone/pkg/kubeconfig"
+	"k8c.io/kubeone/pkg/state"
+	"k8c.io/kubeone/pkg/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// collectCluster collects the nodes, pods and etcd members, same as shown
+// by kubectl and etcdctl
+func collectCluster(s *state.State, b *bundle) {
+	if s.DynamicClient == nil {
+		if err := kubeconfig.BuildKubernetesClientset(s); err != nil {
+			b.addError("kubernetes clientset", err)
+		}
+	}
+
+	if s.DynamicClient != nil {
+		var nodes corev1.NodeList
+		if err := s.DynamicClient.List(s.Context, &nodes); err != nil {
+			b.addError("nodes", err)
+		} else {
+			b.add("cluster/nodes.txt", nodesReport(nodes))
+		}
+
+		var pods corev1.PodList
+		if err := s.DynamicClient.List(s.Context, &pods); err != nil {
+			b.addError("pods", err)
+		} else {
+			b.add("cluster/pods.txt", podsReport(pods))
+		}
+	}
+
+	members, err := etcdstatus.MemberList(s)
+	if err != nil {
+		b.addError("etcd members", err)
+		return
+	}
+
+	var out strings.Builder
+	printer := tabwriter.GetNewTabWriter(&out)
+	fmt.Fprintln(printer, "ID\tNAME\tPEER ADDRS\tCLIENT ADDRS\t")
+	for _, member := range members.Members {
+		fmt.Fprintf(printer, "%x\t%s\t%s\t%s\t\n", member.ID, member.Name, strings.Join(member.PeerURLs, ","), strings.Join(member.ClientURLs, ","))
+	}
+	printer.Flush()
+
+	b.add("cluster/etcd-members.txt", out.String())
+}
+
+func nodesReport(nodes corev1.NodeList) string {
+	var out strings.Builder
+
+	printer := tabwriter.GetNewTabWriter(&out)
+	fmt.Fprintln(printer, "NAME\tSTATUS\tVERSION\tINTERNAL-IP\tOS-IMAGE\tCONTAINER-RUNTIME\t")
+
+	for _, node := range nodes.Items {
+		status := "NotReady"
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady && cond.Status == corev1.ConditionTrue {
+				status = "Ready"
+			}
+		}
+		if node.Spec.Unschedulable {
+			status += ",SchedulingDisabled"
+		}
+
+		internalIP := "<none>"
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP {
+				internalIP = addr.Address
+				break
+			}
+		}
+
+		fmt.Fprintf(printer, "%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			node.Name,
+			status,
+			node.Status.NodeInfo.KubeletVersion,
+			internalIP,
+			node.Status.NodeInfo.OSImage,
+			node.Status.NodeInfo.ContainerRuntimeVersion)
+	}
+	printer.Flush()
+
+	return out.String()
+}
+
+func podsReport(pods corev1.PodList) string {
+	var out strings.Builder
+
+	printer := tabwriter.GetNewTabWriter(&out)
+	fmt.Fprintln(printer, "NAMESPACE\tNAME\tREADY\tSTATUS\tRESTARTS\tNODE\t")
+
+	for _, pod := range pods.Items {
+		ready, restarts := 0, int32(0)
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.Ready {
+				ready++
+			}
+			restarts += container.RestartCount
+		}
+
+		status := string(pod.Status.Phase)
+		if pod.Status.Reason != "" {
+			status = pod.Status.Reason
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+				status = container.State.Waiting.Reason
+			}
+		}
+		if pod.DeletionTimestamp != nil {
+			status = "Terminating"
+		}
+
+		fmt.Fprintf(printer, "%s\t%s\t%d/%d\t%s\t%d\t%s\t\n",
+			pod.Namespace,
+			pod.Name,
+			ready,
+			len(pod.Spec.Containers),
+			status,
+			restarts,
+			pod.Spec.NodeName)
+	}
+	printer.Flush()
+
+	return out.String()
+}
+
+// probesReport summarizes the results of the host probes
+func probesReport(cluster *state.Cluster) string {
+	var out strings.Builder
+
+	printer := tabwriter.GetNewTabWriter(&out)
+	fmt.Fprintln(printer, "HOST\tHOSTNAME\tROLE\tCONTAINERD\tDOCKER\tKUBELET\tAPISERVER\tETCD\tIN-CLUSTER\t")
+
+	printHosts := func(role string, hosts []state.Host) {
+		for _, host := range hosts {
+			fmt.Fprintf(printer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+				host.Config.PublicAddress,
+				host.Config.Hostname,
+				role,
+				componentReport(host.ContainerRuntimeContainerd),
+				componentReport(host.ContainerRuntimeDocker),
+				componentReport(host.Kubelet),
+				containerReport(role, host.APIServer),
+				containerReport(role, host.Etcd),
+				strconv.FormatBool(host.IsInCluster))
+		}
+	}
+	printHosts("control-plane", cluster.ControlPlane)
+	printHosts("worker", cluster.StaticWorkers)
+	printer.Flush()
+
+	return out.String()
+}
+
+func componentReport(component state.ComponentStatus) string {
+	var status string
+
+	switch {
+	case component.Status&state.SystemDStatusRestarting != 0:
+		status = "restarting"
+	case component.Status&state.SystemDStatusRunning != 0:
+		status = "running"
+	case component.Status&state.SystemDStatusActive != 0:
+		status = "active"
+	case component.Status&state.SystemdDStatusDead != 0:
+		status = "dead"
+	case component.Status&state.ComponentInstalled != 0:
+		status = "installed"
+	default:
+		return "-"
+	}
+
+	if component.Version != nil {
+		status = fmt.Sprintf("%s (%s)", component.Version, status)
+	}
+
+	return status
+}
+
+func containerReport(role string, container state.ContainerStatus) string {
+	if role != "control-plane" {
+		return "-"
+	}
+	if container.Healthy() {
+		return "running"
+	}
+
+	return "not running"
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"regexp"
+	"strings"
+
+	"k8c.io/kubeone/pkg/redact"
+)
+
+var (
+	// bootstrapTokenRegexp matches the kubeadm bootstrap tokens
+	bootstrapTokenRegexp = regexp.MustCompile(`\b[a-z0-9]{6}\.[a-z0-9]{16}\b`)
+
+	privateKeyRegexp = regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----.*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)
+
+	// secretFieldRegexp matches YAML, INI and environment variable fields
+	// with secret values
+	secretFieldRegexp = regexp.MustCompile(`(?im)^([ \t]*(?:-[ \t]*)?"?[\w.-]*(?:token|password|secret|client-key-data|certificate[-_]?key|credentials|access[-_]?key|api[-_]?key)[\w.-]*"?[ \t]*[:=][ \t]*)(\S.*)$`)
+
+	// secretFlagRegexp matches command line flags with secret values
+	secretFlagRegexp = regexp.MustCompile(`(?i)(--[\w-]*(?:token|password|secret|certificate-key)[\w-]*[= ])(\S+)`)
+)
+
+// Redactor scrubs secrets from the collected content
+type Redactor struct {
+	secrets *redact.Replacer
+}
+
+// NewRedactor creates the Redactor, scrubbing the well-known secrets (e.g.
+// bootstrap tokens and private keys), and the given secret values
+func NewRedactor(secrets ...string) *Redactor {
+	return &Redactor{secrets: redact.NewReplacer(secrets)}
+}
+
+// Redact returns the content with secrets replaced by redact.Redacted
+func (r *Redactor) Redact(content string) string {
+	content = r.secrets.Replace(content)
+
+	content = privateKeyRegexp.ReplaceAllString(content, redact.Redacted)
+	content = bootstrapTokenRegexp.ReplaceAllString(content, redact.Redacted)
+	content = secretFieldRegexp.ReplaceAllStringFunc(content, func(field string) string {
+		return redactValue(secretFieldRegexp, field)
+	})
+	content = secretFlagRegexp.ReplaceAllStringFunc(content, func(flag string) string {
+		return redactValue(secretFlagRegexp, flag)
+	})
+
+	return content
+}
+
+// redactValue replaces the value of the matched field, unless it's a file
+// path (e.g. --service-account-key-file) or a hash (e.g.
+// --discovery-token-ca-cert-hash), which are useful for debugging
+func redactValue(re *regexp.Regexp, match string) string {
+	groups := re.FindStringSubmatch(match)
+	name, value := groups[1], groups[2]
+
+	unquoted := strings.Trim(value, `"'`)
+	if strings.HasPrefix(unquoted, "/") || strings.HasPrefix(unquoted, "sha256:") || value == redact.Redacted {
+		return match
+	}
+
+	return name + redact.Redacted
+}
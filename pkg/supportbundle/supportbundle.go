@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package supportbundle collects diagnostics of the cluster hosts and the
+// cluster into the archive, with secrets scrubbed.
+package supportbundle
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+	"k8c.io/kubeone/pkg/archive"
+	"k8c.io/kubeone/pkg/credentials"
+	"k8c.io/kubeone/pkg/ssh"
+	"k8c.io/kubeone/pkg/state"
+	"k8c.io/kubeone/pkg/tasks"
+)
+
+const (
+	// journalLines is the number of the most recent journal entries collected
+	// per unit
+	journalLines = 10000
+	// podLogLines is the number of the most recent lines collected per
+	// static pod log
+	podLogLines = 5000
+
+	errorsFile = "errors.txt"
+)
+
+var (
+	journalUnits = []string{"kubelet", "containerd", "docker"}
+
+	staticPods = []string{"etcd", "kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+)
+
+// bundle adds redacted files to the archive, and records errors of the
+// collection, which doesn't stop on the first error
+type bundle struct {
+	lock     sync.Mutex
+	archive  archive.Archive
+	redactor *Redactor
+	errs     []string
+}
+
+func (b *bundle) add(filename, content string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := b.archive.Add(filename, b.redactor.Redact(content)); err != nil {
+		b.errs = append(b.errs, fmt.Sprintf("%s: %v", filename, err))
+	}
+}
+
+func (b *bundle) addError(what string, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.errs = append(b.errs, b.redactor.Redact(fmt.Sprintf("%s: %v", what, err)))
+}
+
+// Collect collects the diagnostics from all hosts over SSH, and from the
+// cluster API, into the tar.gz archive. Failing to collect some of the
+// diagnostics is recorded in the archive, and doesn't fail the collection.
+func Collect(s *state.State, filename string) error {
+	// secrets may be missing in the credentials file and the environment
+	// when collecting diagnostics, so errors are ignored
+	creds, _ := credentials.Any(s.Cluster.CloudProvider, s.CredentialsFilePath)
+	secrets := []string{}
+	for _, value := range creds {
+		secrets = append(secrets, value)
+	}
+	secrets = append(secrets, s.Secrets...)
+
+	arch, err := archive.NewTarGzip(filename)
+	if err != nil {
+		return errors.Wrap(err, "failed to create support bundle")
+	}
+	defer arch.Close()
+
+	b := &bundle{
+		archive:  arch,
+		redactor: NewRedactor(secrets...),
+	}
+
+	s.Logger.Info("Running probes...")
+	probes := tasks.WithProbes(tasks.WithHostnameOS(nil))
+	for i := range probes {
+		// diagnostics are usually collected from broken clusters, where
+		// retrying the probes only delays the collection
+		probes[i].Retries = 1
+	}
+	if err = probes.Run(s); err != nil {
+		b.addError("probes", err)
+	}
+	if s.LiveCluster != nil {
+		b.add("cluster/probes.txt", probesReport(s.LiveCluster))
+	}
+
+	s.Logger.Info("Collecting host diagnostics...")
+	hosts := append(append([]kubeoneapi.HostConfig{}, s.Cluster.ControlPlane.Hosts...), s.Cluster.StaticWorkers.Hosts...)
+	err = s.RunTaskOnNodes(hosts, func(s *state.State, node *kubeoneapi.HostConfig, _ ssh.Connection) error {
+		collectHost(s, node, b)
+		return nil
+	}, state.RunParallel)
+	if err != nil {
+		b.addError("hosts", err)
+	}
+
+	s.Logger.Info("Collecting cluster diagnostics...")
+	collectCluster(s, b)
+
+	if len(b.errs) > 0 {
+		s.Logger.Warnf("Some diagnostics could not be collected, see %s in the support bundle", errorsFile)
+		b.add(errorsFile, strings.Join(b.errs, "\n")+"\n")
+	}
+
+	return nil
+}
+
+func collectHost(s *state.State, node *kubeoneapi.HostConfig, b *bundle) {
+	hostDir := path.Join("hosts", node.PublicAddress)
+
+	run := func(cmd string) (string, error) {
+		var stdout, stderr strings.Builder
+
+		if _, err := s.Runner.Stream(cmd, &stdout, &stderr); err != nil {
+			return "", errors.Wrap(err, strings.TrimSpace(stderr.String()))
+		}
+
+		return stdout.String(), nil
+	}
+
+	collect := func(filename, cmd string) {
+		out, err := run(cmd)
+		if err != nil {
+			b.addError(path.Join(hostDir, filename), err)
+			return
+		}
+		b.add(path.Join(hostDir, filename), out)
+	}
+
+	// collectFiles collects the files listed by the command, keeping their
+	// paths in the archive
+	collectFiles := func(listCmd string, readCmd func(file string) string) {
+		out, err := run(listCmd)
+		if err != nil {
+			b.addError(path.Join(hostDir, "files"), err)
+			return
+		}
+
+		for _, file := range strings.Fields(out) {
+			collect(path.Join("files", strings.TrimPrefix(file, "./")), readCmd(file))
+		}
+	}
+
+	collect("os-release", "cat /etc/os-release")
+
+	for _, unit := range journalUnits {
+		collect(path.Join("journal", unit+".log"), fmt.Sprintf("sudo journalctl --unit=%s --no-pager --lines=%d", unit, journalLines))
+	}
+
+	catFile := func(file string) string { return "sudo cat -- " + quote(file) }
+
+	collectFiles("sudo "+findKubernetesFilesCmd("/etc/kubernetes"), catFile)
+
+	// only the generated YAML configs are collected from the work directory,
+	// leaving out the cloud-config uploaded before it's moved to /etc/kubernetes
+	collectFiles(fmt.Sprintf(`find %s/cfg -type f -name '*.yaml' 2>/dev/null || true`, quote(s.WorkDir)), catFile)
+
+	podPaths := []string{}
+	for _, pod := range staticPods {
+		podPaths = append(podPaths, fmt.Sprintf("-path '/var/log/pods/kube-system_%s-*'", pod))
+	}
+	collectFiles(
+		fmt.Sprintf(`sudo find /var/log/pods -type f -name '*.log' \( %s \) 2>/dev/null || true`, strings.Join(podPaths, " -o ")),
+		func(file string) string { return fmt.Sprintf("sudo tail --lines=%d -- %s", podLogLines, quote(file)) },
+	)
+}
+
+// findKubernetesFilesCmd lists the files of the Kubernetes configuration
+// directory. Certificates and keys, and the cloud-config holding the cloud
+// provider credentials in a provider specific format the redaction can't be
+// relied on for, are not collected.
+func findKubernetesFilesCmd(dir string) string {
+	return fmt.Sprintf(`find %s -type f -not -path %s -not -path %s 2>/dev/null || true`,
+		quote(dir), quote(path.Join(dir, "pki")+"/*"), quote(path.Join(dir, "cloud-config")))
+}
+
+// quote quotes the argument for the shell
+func quote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supportbundle
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestFindKubernetesFilesCmd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-supportbundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{
+		"admin.conf",
+		"cloud-config",
+		"manifests/kube-apiserver.yaml",
+		"manifests/cloud-config",
+		"pki/ca.key",
+		"pki/etcd/ca.crt",
+	}
+	for _, file := range files {
+		file = filepath.Join(dir, file)
+		if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(file, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := exec.Command("sh", "-c", findKubernetesFilesCmd(dir)).Output()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := []string{}
+	for _, file := range strings.Fields(string(out)) {
+		found = append(found, strings.TrimPrefix(file, dir+"/"))
+	}
+	sort.Strings(found)
+
+	// only the top-level cloud-config is the one holding the credentials
+	expected := []string{"admin.conf", "manifests/cloud-config", "manifests/kube-apiserver.yaml"}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected files %v, got %v", expected, found)
+	}
+}
@@ -33,9 +33,8 @@ func joinControlplaneNode(s *state.State) error {
 func joinControlPlaneNodeInternal(s *state.State, node *kubeoneapi.HostConfig, conn ssh.Connection) error {
 	logger := s.Logger.WithField("node", node.PublicAddress)
 
-	sleepTime := 15 * time.Second
-	logger.Infof("Waiting %s to ensure main control plane components are up...", sleepTime)
-	time.Sleep(sleepTime)
+	logger.Infof("Waiting %s to ensure main control plane components are up...", timeoutControlPlaneJoin)
+	time.Sleep(timeoutControlPlaneJoin)
 
 	logger.Info("Joining control plane node")
 	cmd, err := scripts.KubeadmJoin(s.WorkDir, node.ID, s.KubeadmVerboseFlag())
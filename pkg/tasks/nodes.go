@@ -17,6 +17,9 @@ limitations under the License.
 package tasks
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/pkg/errors"
 
 	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
@@ -25,10 +28,21 @@ import (
 	"k8c.io/kubeone/pkg/state"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/retry"
 )
 
+// The ownership annotations hold the labels, annotations and taints set by
+// kubeone, so only those are removed from the node when removed from the
+// manifest
+const (
+	annotationManagedLabels      = "kubeone.io/managed-labels"
+	annotationManagedAnnotations = "kubeone.io/managed-annotations"
+	annotationManagedTaints      = "kubeone.io/managed-taints"
+)
+
 func drainNode(s *state.State, node kubeoneapi.HostConfig) error {
 	cmd, err := scripts.DrainNode(node.Hostname)
 	if err != nil {
@@ -87,3 +101,150 @@ func restartKubeAPIServerDocker(s *state.State) error {
 
 	return errors.WithStack(err)
 }
+
+// ensureNodeMetadata reconciles the labels, annotations and taints of the
+// control plane and static worker nodes with the manifest
+func ensureNodeMetadata(s *state.State) error {
+	s.Logger.Infoln("Ensuring node labels, annotations and taints...")
+
+	for _, host := range s.Cluster.ControlPlane.Hosts {
+		if err := ensureHostNodeMetadata(s, host, true); err != nil {
+			return err
+		}
+	}
+	for _, host := range s.Cluster.StaticWorkers.Hosts {
+		if err := ensureHostNodeMetadata(s, host, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ensureHostNodeMetadata(s *state.State, host kubeoneapi.HostConfig, controlPlane bool) error {
+	updateErr := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var node corev1.Node
+
+		if err := s.DynamicClient.Get(s.Context, types.NamespacedName{Name: host.Hostname}, &node); err != nil {
+			return err
+		}
+
+		if !reconcileNodeMetadata(&node, host, controlPlane) {
+			return nil
+		}
+
+		s.Logger.Debugf("Updating labels, annotations and taints of the node %q", host.Hostname)
+		return s.DynamicClient.Update(s.Context, &node)
+	})
+
+	if k8serrors.IsNotFound(updateErr) {
+		s.Logger.Warnf("Node %q not found, skipping labels, annotations and taints", host.Hostname)
+		return nil
+	}
+
+	return errors.Wrapf(updateErr, "failed to update labels, annotations and taints of the node %q", host.Hostname)
+}
+
+// reconcileNodeMetadata sets the labels, annotations and taints of the host on
+// the node, removing those previously set by kubeone and since removed from
+// the manifest. It returns whether the node was changed.
+func reconcileNodeMetadata(node *corev1.Node, host kubeoneapi.HostConfig, controlPlane bool) bool {
+	original := node.DeepCopy()
+
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	if node.Labels == nil {
+		node.Labels = map[string]string{}
+	}
+
+	reconcileNodeMap(node.Labels, host.Labels, node.Annotations, annotationManagedLabels)
+	reconcileNodeMap(node.Annotations, host.Annotations, node.Annotations, annotationManagedAnnotations)
+	reconcileNodeTaints(node, host.Taints, controlPlane)
+
+	return !equality.Semantic.DeepEqual(original, node)
+}
+
+// reconcileNodeMap sets the desired values, removing the values listed in the
+// ownership annotation and no longer desired, and updates the ownership annotation
+func reconcileNodeMap(current, desired, annotations map[string]string, ownershipKey string) {
+	for _, key := range splitManaged(annotations[ownershipKey]) {
+		if _, ok := desired[key]; !ok {
+			delete(current, key)
+		}
+	}
+
+	keys := []string{}
+	for k, v := range desired {
+		current[k] = v
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		delete(annotations, ownershipKey)
+		return
+	}
+
+	sort.Strings(keys)
+	annotations[ownershipKey] = strings.Join(keys, ",")
+}
+
+// reconcileNodeTaints sets the desired taints, removing the taints listed in
+// the ownership annotation and no longer desired. Nodes without the ownership
+// annotation were tainted only by kubeadm at join time, and the control plane
+// taint is the only taint known to be set by kubeone then.
+func reconcileNodeTaints(node *corev1.Node, desired []corev1.Taint, controlPlane bool) {
+	managed := map[string]bool{}
+	if ownership, ok := node.Annotations[annotationManagedTaints]; ok {
+		for _, id := range splitManaged(ownership) {
+			managed[id] = true
+		}
+	} else if controlPlane {
+		managed[taintID(corev1.Taint{Key: labelControlPlaneNode, Effect: corev1.TaintEffectNoSchedule})] = true
+	}
+
+	desiredByID := map[string]corev1.Taint{}
+	ids := []string{}
+	for _, taint := range desired {
+		desiredByID[taintID(taint)] = taint
+		ids = append(ids, taintID(taint))
+	}
+
+	taints := []corev1.Taint{}
+	for _, taint := range node.Spec.Taints {
+		id := taintID(taint)
+		if desiredTaint, ok := desiredByID[id]; ok {
+			taint.Value = desiredTaint.Value
+			delete(desiredByID, id)
+		} else if managed[id] {
+			continue
+		}
+		taints = append(taints, taint)
+	}
+	for _, taint := range desired {
+		if _, ok := desiredByID[taintID(taint)]; ok {
+			taints = append(taints, taint)
+			delete(desiredByID, taintID(taint))
+		}
+	}
+
+	node.Spec.Taints = taints
+	// the annotation is kept even without taints, to tell apart the nodes
+	// never reconciled
+	sort.Strings(ids)
+	node.Annotations[annotationManagedTaints] = strings.Join(ids, ",")
+}
+
+// taintID identifies the taint, as there can be only one taint with the same
+// key and effect
+func taintID(taint corev1.Taint) string {
+	return taint.Key + ":" + string(taint.Effect)
+}
+
+func splitManaged(ownership string) []string {
+	if ownership == "" {
+		return nil
+	}
+
+	return strings.Split(ownership, ",")
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"reflect"
+	"testing"
+
+	kubeoneapi "k8c.io/kubeone/pkg/apis/kubeone"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileNodeMetadata(t *testing.T) {
+	t.Parallel()
+
+	masterTaint := corev1.Taint{Key: labelControlPlaneNode, Effect: corev1.TaintEffectNoSchedule}
+	uninitializedTaint := corev1.Taint{Key: "node.cloudprovider.kubernetes.io/uninitialized", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+
+	testcases := []struct {
+		name            string
+		node            corev1.Node
+		host            kubeoneapi.HostConfig
+		controlPlane    bool
+		expectedNode    corev1.Node
+		expectedChanged bool
+	}{
+		{
+			name: "labels and annotations are set",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"kubernetes.io/hostname": "worker1"}},
+			},
+			host: kubeoneapi.HostConfig{
+				Labels:      map[string]string{"example.com/zone": "a", "example.com/disk": "ssd"},
+				Annotations: map[string]string{"example.com/owner": "team-a"},
+				Taints:      []corev1.Taint{},
+			},
+			expectedNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"kubernetes.io/hostname": "worker1", "example.com/zone": "a", "example.com/disk": "ssd"},
+					Annotations: map[string]string{
+						"example.com/owner":          "team-a",
+						annotationManagedLabels:      "example.com/disk,example.com/zone",
+						annotationManagedAnnotations: "example.com/owner",
+						annotationManagedTaints:      "",
+					},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{}},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "only managed labels are removed",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"kubernetes.io/hostname": "worker1", "example.com/zone": "a", "example.com/disk": "ssd"},
+					Annotations: map[string]string{
+						annotationManagedLabels: "example.com/disk,example.com/zone",
+						annotationManagedTaints: "",
+					},
+				},
+			},
+			host: kubeoneapi.HostConfig{
+				Labels: map[string]string{"example.com/zone": "b"},
+				Taints: []corev1.Taint{},
+			},
+			expectedNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"kubernetes.io/hostname": "worker1", "example.com/zone": "b"},
+					Annotations: map[string]string{
+						annotationManagedLabels: "example.com/zone",
+						annotationManagedTaints: "",
+					},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{}},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "up to date node is unchanged",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"example.com/zone": "a"},
+					Annotations: map[string]string{annotationManagedLabels: "example.com/zone", annotationManagedTaints: ""},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{uninitializedTaint}},
+			},
+			host: kubeoneapi.HostConfig{
+				Labels: map[string]string{"example.com/zone": "a"},
+				Taints: []corev1.Taint{},
+			},
+			expectedNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"example.com/zone": "a"},
+					Annotations: map[string]string{annotationManagedLabels: "example.com/zone", annotationManagedTaints: ""},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{uninitializedTaint}},
+			},
+		},
+		{
+			name: "control plane taint set at join time is removed",
+			node: corev1.Node{
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{masterTaint, uninitializedTaint}},
+			},
+			host:         kubeoneapi.HostConfig{Taints: []corev1.Taint{}},
+			controlPlane: true,
+			expectedNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{},
+					Annotations: map[string]string{annotationManagedTaints: ""},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{uninitializedTaint}},
+			},
+			expectedChanged: true,
+		},
+		{
+			name: "managed taints are updated",
+			node: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{annotationManagedTaints: "dedicated:NoSchedule,gpu:NoExecute"},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+					{Key: "dedicated", Value: "team-a", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "gpu", Effect: corev1.TaintEffectNoExecute},
+				}},
+			},
+			host: kubeoneapi.HostConfig{Taints: []corev1.Taint{
+				{Key: "dedicated", Value: "team-b", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+			}},
+			expectedNode: corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{},
+					Annotations: map[string]string{annotationManagedTaints: "dedicated:NoSchedule,spot:PreferNoSchedule"},
+				},
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{
+					{Key: "dedicated", Value: "team-b", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+				}},
+			},
+			expectedChanged: true,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			node := tc.node.DeepCopy()
+			changed := reconcileNodeMetadata(node, tc.host, tc.controlPlane)
+			if changed != tc.expectedChanged {
+				t.Errorf("expected changed %t, got %t", tc.expectedChanged, changed)
+			}
+			if !reflect.DeepEqual(*node, tc.expectedNode) {
+				t.Errorf("expected node:\n%+v\ngot:\n%+v", tc.expectedNode, *node)
+			}
+		})
+	}
+}
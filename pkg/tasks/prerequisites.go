@@ -33,7 +33,8 @@ func installPrerequisites(s *state.State) error {
 }
 
 func generateConfigurationFiles(s *state.State) error {
-	s.Configuration.AddFile("cfg/cloud-config", s.Cluster.CloudProvider.CloudConfig)
+	// the cloud config contains credentials
+	s.Configuration.AddFileWithMode("cfg/cloud-config", s.Cluster.CloudProvider.CloudConfig, 0600)
 
 	if s.Cluster.Features.StaticAuditLog != nil && s.Cluster.Features.StaticAuditLog.Enable {
 		if err := s.Configuration.AddFilePath("cfg/audit-policy.yaml", s.Cluster.Features.StaticAuditLog.Config.PolicyFilePath, s.ManifestFilePath); err != nil {
@@ -349,7 +349,7 @@ func systemdUnitInfo(name string, conn ssh.Connection, opts ...systemdUnitInfoOp
 func withFlatcarContainerRuntimeVersion(component *state.ComponentStatus, conn ssh.Connection) error {
 	cmd := versionCmdGenerator(fmt.Sprintf("/run/torcx/bin/%s", component.Name))
 
-	out, _, _, err := conn.Exec(cmd)
+	out, _, _, err := ssh.ExecIdempotent(conn, cmd)
 	if err != nil {
 		return err
 	}
@@ -371,7 +371,7 @@ func withComponentVersion(versionCmdGenerator func(string) string) systemdUnitIn
 			return err
 		}
 
-		out, _, _, err := conn.Exec(versionCmdGenerator(execPath))
+		out, _, _, err := ssh.ExecIdempotent(conn, versionCmdGenerator(execPath))
 		if err != nil {
 			return err
 		}
@@ -388,7 +388,7 @@ func withComponentVersion(versionCmdGenerator func(string) string) systemdUnitIn
 }
 
 func detectKubeletInitialized(host *state.Host, conn ssh.Connection) error {
-	_, _, exitcode, err := conn.Exec(kubeletInitializedCMD)
+	_, _, exitcode, err := ssh.ExecIdempotent(conn, kubeletInitializedCMD)
 	if err != nil && exitcode <= 0 {
 		// If there's an error and exit code is 0, there's mostly like a connection
 		// error. If exit code is -1, there might be a session problem.
@@ -403,7 +403,7 @@ func detectKubeletInitialized(host *state.Host, conn ssh.Connection) error {
 }
 
 func systemdUnitExecStartPath(conn ssh.Connection, unitName string) (string, error) {
-	out, _, _, err := conn.Exec(fmt.Sprintf(systemdShowExecStartCMD, unitName))
+	out, _, _, err := ssh.ExecIdempotent(conn, fmt.Sprintf(systemdShowExecStartCMD, unitName))
 	if err != nil {
 		return "", err
 	}
@@ -422,7 +422,7 @@ func systemdUnitExecStartPath(conn ssh.Connection, unitName string) (string, err
 }
 
 func systemdStatus(conn ssh.Connection, service string) (uint64, error) {
-	out, _, _, err := conn.Exec(fmt.Sprintf(systemdShowStatusCMD, service))
+	out, _, _, err := ssh.ExecIdempotent(conn, fmt.Sprintf(systemdShowStatusCMD, service))
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,275 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tasks
+
+import (
+	"context"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"k8c.io/kubeone/pkg/apis/kubeone/config"
+	"k8c.io/kubeone/pkg/ssh/sshfake"
+	"k8c.io/kubeone/pkg/state"
+
+	clusterv1alpha1 "github.com/kubermatic/machine-controller/pkg/apis/cluster/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsscheme "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	apiregscheme "k8s.io/kube-aggregator/pkg/client/clientset_generated/clientset/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const simulatedCluster = `
+apiVersion: kubeone.io/v1beta1
+kind: KubeOneCluster
+name: simulated
+versions:
+  kubernetes: "1.20.4"
+cloudProvider:
+  none: {}
+controlPlane:
+  hosts:
+  - publicAddress: 192.168.1.1
+    privateAddress: 10.0.0.1
+    sshPrivateKeyFile: /dev/null
+  - publicAddress: 192.168.1.2
+    privateAddress: 10.0.0.2
+    sshPrivateKeyFile: /dev/null
+  - publicAddress: 192.168.1.3
+    privateAddress: 10.0.0.3
+    sshPrivateKeyFile: /dev/null
+machineController:
+  deploy: false
+`
+
+// simulation runs tasks against simulated hosts
+type simulation struct {
+	hosts []*sshfake.Host
+	state *state.State
+}
+
+func newSimulation(t *testing.T, newHost func(address, hostname string) *sshfake.Host) *simulation {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	cluster, err := config.BytesToKubeOneCluster([]byte(simulatedCluster), nil, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := []*sshfake.Host{}
+	for i, host := range cluster.ControlPlane.Hosts {
+		hosts = append(hosts, newHost(host.PublicAddress, "cp-"+string(rune('0'+i))))
+	}
+
+	// kubeadm generates the PKI on the leader, which is then copied to the
+	// working directory to be downloaded
+	hosts[0].On(`sudo cp /etc/kubernetes/pki/ca.crt`, sshfake.Response{
+		Fn: func(h *sshfake.Host, _ string, _ []byte) sshfake.Response {
+			for _, f := range []string{"ca.crt", "ca.key", "sa.key", "sa.pub", "front-proxy-ca.crt", "front-proxy-ca.key", "etcd/ca.crt", "etcd/ca.key"} {
+				h.WriteFile("./kubeone/pki/"+f, []byte(f))
+			}
+			return sshfake.Response{}
+		},
+	})
+
+	// do not wait for components to settle on simulated hosts
+	nodeUpgrade, controlPlaneJoin := timeoutNodeUpgrade, timeoutControlPlaneJoin
+	timeoutNodeUpgrade, timeoutControlPlaneJoin = 0, 0
+	t.Cleanup(func() {
+		timeoutNodeUpgrade, timeoutControlPlaneJoin = nodeUpgrade, controlPlaneJoin
+	})
+
+	s, err := state.New(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Cluster = cluster
+	s.Logger = logger
+	s.Connector = sshfake.NewConnector(s.Context, hosts...)
+	s.DynamicClient = fake.NewFakeClientWithScheme(simulationScheme(t))
+
+	return &simulation{hosts: hosts, state: s}
+}
+
+func simulationScheme(t *testing.T) *kruntime.Scheme {
+	scheme := kruntime.NewScheme()
+	for _, addToScheme := range []func(*kruntime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		clusterv1alpha1.AddToScheme,
+		apiextensionsscheme.AddToScheme,
+		apiregscheme.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return scheme
+}
+
+// noop is a stub for tasks not possible to simulate
+func noop(*state.State) error { return nil }
+
+// simulationStubs replace tasks requiring the live Kubernetes API server,
+// as the fake dynamic client is used instead
+var simulationStubs = map[string]func(*state.State) error{
+	"k8c.io/kubeone/pkg/kubeconfig.BuildKubernetesClientset": noop,
+	"k8c.io/kubeone/pkg/tasks.repairClusterIfNeeded":         noop,
+	"k8c.io/kubeone/pkg/tasks.saveKubeconfig":                noop,
+	"k8c.io/kubeone/pkg/tasks.ensureCNI":                     noop,
+}
+
+func funcName(fn func(*state.State) error) string {
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
+
+// run runs the tasks once (without retries), replacing the tasks talking to
+// the Kubernetes API with the given stubs
+func (sim *simulation) run(tasks Tasks, stubs map[string]func(*state.State) error) error {
+	for i := range tasks {
+		tasks[i].Retries = 1
+		if stub, ok := stubs[funcName(tasks[i].Fn)]; ok {
+			tasks[i].Fn = stub
+		} else if stub, ok := simulationStubs[funcName(tasks[i].Fn)]; ok {
+			tasks[i].Fn = stub
+		}
+	}
+
+	return tasks.Run(sim.state)
+}
+
+var simulatedOperatingSystems = []struct {
+	name      string
+	newHost   func(address, hostname string) *sshfake.Host
+	installed string
+}{
+	{name: "ubuntu", newHost: sshfake.NewUbuntuHost, installed: `apt-get install`},
+	{name: "centos", newHost: sshfake.NewCentOSHost, installed: `yum install`},
+	{name: "flatcar", newHost: sshfake.NewFlatcarHost, installed: `install .* /opt/bin/\$binary`},
+}
+
+func TestSimulatedFullInstall(t *testing.T) {
+	for _, tc := range simulatedOperatingSystems {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := newSimulation(t, tc.newHost)
+			for _, h := range sim.hosts {
+				h.On(kubeletInitializedCMD, sshfake.Response{ExitCode: 1})
+			}
+
+			if err := sim.run(WithFullInstall(nil), nil); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, h := range sim.hosts {
+				if !h.Ran(tc.installed) {
+					t.Errorf("expected binaries to be installed on host %d", i)
+				}
+				if !h.Ran(`(?s)kubeadm.*init phase certs all`) {
+					t.Errorf("expected certificates to be generated on host %d", i)
+				}
+			}
+			if !sim.hosts[0].Ran(`(?s)kubeadm.*init --config`) {
+				t.Error("expected kubeadm init on the leader")
+			}
+			for i, h := range sim.hosts[1:] {
+				if !h.Ran(`kubeadm join`) {
+					t.Errorf("expected follower %d to join the cluster", i+1)
+				}
+				if h.Ran(`(?s)kubeadm.*init --config`) {
+					t.Errorf("expected no kubeadm init on follower %d", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatedUpgrade(t *testing.T) {
+	for _, tc := range simulatedOperatingSystems {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := newSimulation(t, tc.newHost)
+			for _, h := range sim.hosts {
+				// probing the live cluster requires the API server and etcd
+				h.On(kubeletInitializedCMD, sshfake.Response{ExitCode: 1})
+			}
+			for i := range sim.hosts {
+				node := &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cp-" + string(rune('0'+i)),
+						Labels: map[string]string{labelControlPlaneNode: ""},
+						// the fake client doesn't set the creation timestamp
+						CreationTimestamp: metav1.Now(),
+					},
+				}
+				if err := sim.state.DynamicClient.Create(sim.state.Context, node); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stubs := map[string]func(*state.State) error{
+				// preflight checks verify the nodes using the live API server
+				"k8c.io/kubeone/pkg/tasks.runPreflightChecks": noop,
+			}
+			if err := sim.run(WithUpgrade(nil), stubs); err != nil {
+				t.Fatal(err)
+			}
+
+			if !sim.hosts[0].Ran(`kubeadm .*upgrade apply`) {
+				t.Error("expected kubeadm upgrade apply on the leader")
+			}
+			for i, h := range sim.hosts[1:] {
+				if !h.Ran(`kubeadm .*upgrade node`) {
+					t.Errorf("expected kubeadm upgrade node on follower %d", i+1)
+				}
+			}
+			for i := range sim.hosts {
+				if !sim.hosts[0].Ran(`kubectl drain cp-` + string(rune('0'+i))) {
+					t.Errorf("expected host %d to be drained", i)
+				}
+			}
+		})
+	}
+}
+
+func TestSimulatedReset(t *testing.T) {
+	for _, tc := range simulatedOperatingSystems {
+		t.Run(tc.name, func(t *testing.T) {
+			sim := newSimulation(t, tc.newHost)
+			sim.state.RemoveBinaries = true
+
+			if err := sim.run(WithReset(nil), nil); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, h := range sim.hosts {
+				if !h.Ran(`kubeadm .*reset`) {
+					t.Errorf("expected kubeadm reset on host %d", i)
+				}
+				if !h.Ran(`(apt-get remove|yum remove|rm -rf .*/opt/bin/kubeadm)`) {
+					t.Errorf("expected binaries to be removed from host %d", i)
+				}
+			}
+		})
+	}
+}
@@ -157,6 +157,11 @@ func WithRefreshResources(t Tasks) Tasks {
 				Fn:     certificate.DownloadCA,
 				ErrMsg: "failed to download ca from leader",
 			},
+			{
+				Fn:         ensureNodeMetadata,
+				ErrMsg:     "failed to ensure node labels, annotations and taints",
+				Desciption: "ensure node labels, annotations and taints",
+			},
 			{
 				Fn:         machinecontroller.Ensure,
 				ErrMsg:     "failed to ensure machine-controller",
@@ -258,6 +263,7 @@ func kubernetesResources() Tasks {
 		},
 		{Fn: patchCNI, ErrMsg: "failed to patch CNI"},
 		{Fn: joinStaticWorkerNodes, ErrMsg: "failed to join worker nodes to the cluster"},
+		{Fn: ensureNodeMetadata, ErrMsg: "failed to ensure node labels, annotations and taints"},
 		{
 			Fn:         machinecontroller.Ensure,
 			ErrMsg:     "failed to ensure machine-controller",
@@ -40,9 +40,16 @@ import (
 const (
 	labelUpgradeLock      = "kubeone.io/upgrade-in-progress"
 	labelControlPlaneNode = "node-role.kubernetes.io/master"
+)
+
+// waiting times are variables, so they can be shortened in tests
+var (
 	// timeoutNodeUpgrade is time for how long kubeone will wait after finishing the upgrade
 	// process on the node
 	timeoutNodeUpgrade = 30 * time.Second
+	// timeoutControlPlaneJoin is time for how long kubeone will wait before joining
+	// the control plane node, to ensure the main control plane components are up
+	timeoutControlPlaneJoin = 15 * time.Second
 )
 
 func determineHostname(s *state.State) error {
@@ -71,13 +78,18 @@ func determineHostname(s *state.State) error {
 func determineOS(s *state.State) error {
 	s.Logger.Infoln("Determine operating system...")
 	return s.RunTaskOnAllNodes(func(s *state.State, node *kubeoneapi.HostConfig, conn ssh.Connection) error {
-		f, err := conn.File("/etc/os-release", os.O_RDONLY)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+		var buf []byte
+		err := ssh.RetryOnLost(conn, func() error {
+			f, err := conn.File("/etc/os-release", os.O_RDONLY)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			buf, err = ioutil.ReadAll(f)
 
-		buf, err := ioutil.ReadAll(f)
+			return err
+		})
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package terraform reads the Terraform state files, so the Terraform output
+// can be sourced without the terraform binary.
+package terraform
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StateFileName is the name of the local Terraform state file
+	StateFileName = "terraform.tfstate"
+
+	// supportedStateVersion is the version of the state file format written
+	// by Terraform 0.12 and newer, and OpenTofu
+	supportedStateVersion = 4
+
+	workspaceEnvVar  = "TF_WORKSPACE"
+	defaultWorkspace = "default"
+	localBackend     = "local"
+)
+
+type state struct {
+	Version int             `json:"version"`
+	Outputs json.RawMessage `json:"outputs"`
+}
+
+// IsState returns whether the content is the Terraform state file, instead
+// of the terraform output -json output. The outputs are objects, so the state
+// is recognized by its numeric version.
+func IsState(content []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return false
+	}
+
+	var version int
+	return json.Unmarshal(fields["version"], &version) == nil
+}
+
+// OutputFromState returns the outputs of the Terraform state file, in the
+// format of the terraform output -json command
+func OutputFromState(content []byte) ([]byte, error) {
+	s := state{}
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the terraform state")
+	}
+
+	if s.Version != supportedStateVersion {
+		return nil, errors.Errorf("unsupported terraform state version %d, only version %d is supported", s.Version, supportedStateVersion)
+	}
+
+	if len(s.Outputs) == 0 || string(s.Outputs) == "null" {
+		return []byte("{}"), nil
+	}
+
+	return s.Outputs, nil
+}
+
+// backendState is the backend configuration saved by terraform init
+type backendState struct {
+	Backend *struct {
+		Type   string `json:"type"`
+		Config struct {
+			Path string `json:"path"`
+		} `json:"config"`
+	} `json:"backend"`
+}
+
+// LocalStatePath returns the path of the local state file of the current
+// workspace in the Terraform directory. It returns false if the directory is
+// initialized with a remote backend, or its backend configuration can't be
+// read, as the local state file, if any, is stale then.
+func LocalStatePath(dir string) (string, bool) {
+	statePath := filepath.Join(dir, StateFileName)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, ".terraform", StateFileName))
+	switch {
+	case os.IsNotExist(err):
+		// not initialized, or initialized without the backend
+	case err != nil:
+		return "", false
+	default:
+		var backend backendState
+		if err = json.Unmarshal(content, &backend); err != nil {
+			return "", false
+		}
+		if backend.Backend != nil {
+			if backend.Backend.Type != localBackend {
+				return "", false
+			}
+			if path := backend.Backend.Config.Path; path != "" {
+				statePath = path
+				if !filepath.IsAbs(path) {
+					statePath = filepath.Join(dir, path)
+				}
+			}
+		}
+	}
+
+	workspace := os.Getenv(workspaceEnvVar)
+	if workspace == "" {
+		if environment, err := ioutil.ReadFile(filepath.Join(dir, ".terraform", "environment")); err == nil {
+			workspace = strings.TrimSpace(string(environment))
+		}
+	}
+
+	if workspace == "" || workspace == defaultWorkspace {
+		return statePath, true
+	}
+
+	return filepath.Join(dir, "terraform.tfstate.d", workspace, StateFileName), true
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The KubeOne Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package terraform
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOutputFromState(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		isState       bool
+		expected      string
+		expectedError bool
+	}{
+		{
+			name: "state version 4",
+			content: `{
+  "version": 4,
+  "terraform_version": "1.0.0",
+  "serial": 3,
+  "lineage": "6f1c7f8e",
+  "outputs": {
+    "kubeone_api": {"value": {"endpoint": "lb.example.com"}, "type": ["object", {"endpoint": "string"}]},
+    "kubeone_hosts": {"value": {"control_plane": {"cluster_name": "demo"}}, "type": "object", "sensitive": true}
+  },
+  "resources": []
+}`,
+			isState: true,
+			expected: `{
+  "kubeone_api": {"value": {"endpoint": "lb.example.com"}, "type": ["object", {"endpoint": "string"}]},
+  "kubeone_hosts": {"value": {"control_plane": {"cluster_name": "demo"}}, "type": "object", "sensitive": true}
+}`,
+		},
+		{
+			name:     "state without outputs",
+			content:  `{"version": 4, "terraform_version": "1.0.0", "resources": []}`,
+			isState:  true,
+			expected: `{}`,
+		},
+		{
+			name:          "state version 3",
+			content:       `{"version": 3, "terraform_version": "0.11.14", "modules": []}`,
+			isState:       true,
+			expectedError: true,
+		},
+		{
+			name:    "terraform output",
+			content: `{"version": {"value": "1.0.0", "type": "string"}, "kubeone_api": {"value": {"endpoint": "lb.example.com"}}}`,
+		},
+		{
+			name:    "invalid JSON",
+			content: `version: 4`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if isState := IsState([]byte(tc.content)); isState != tc.isState {
+				t.Fatalf("expected IsState %t, got %t", tc.isState, isState)
+			}
+			if !tc.isState {
+				return
+			}
+
+			output, err := OutputFromState([]byte(tc.content))
+			if tc.expectedError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var got, expected interface{}
+			if err = json.Unmarshal(output, &got); err != nil {
+				t.Fatalf("output is not valid JSON: %v", err)
+			}
+			if err = json.Unmarshal([]byte(tc.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("expected output %s, got %s", tc.expected, output)
+			}
+		})
+	}
+}
+
+func TestLocalStatePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeone-terraform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if path, ok := LocalStatePath(dir); !ok || path != filepath.Join(dir, StateFileName) {
+		t.Errorf("expected the default workspace state, got %q", path)
+	}
+
+	if err = os.MkdirAll(filepath.Join(dir, ".terraform"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, ".terraform", "environment"), []byte("staging"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if path, ok := LocalStatePath(dir); !ok || path != filepath.Join(dir, "terraform.tfstate.d", "staging", StateFileName) {
+		t.Errorf("expected the staging workspace state, got %q", path)
+	}
+
+	os.Setenv(workspaceEnvVar, "production")
+	defer os.Unsetenv(workspaceEnvVar)
+
+	if path, ok := LocalStatePath(dir); !ok || path != filepath.Join(dir, "terraform.tfstate.d", "production", StateFileName) {
+		t.Errorf("expected the production workspace state, got %q", path)
+	}
+}
+
+func TestLocalStatePathBackend(t *testing.T) {
+	tests := []struct {
+		name string
+		// backend is the content of .terraform/terraform.tfstate, which is
+		// not written if empty
+		backend       string
+		expectedPath  string
+		expectedLocal bool
+	}{
+		{
+			name:          "not initialized",
+			expectedPath:  StateFileName,
+			expectedLocal: true,
+		},
+		{
+			name:          "initialized without backend",
+			backend:       `{"version": 3, "serial": 1}`,
+			expectedPath:  StateFileName,
+			expectedLocal: true,
+		},
+		{
+			name:          "local backend",
+			backend:       `{"version": 3, "backend": {"type": "local", "config": {"path": null}}}`,
+			expectedPath:  StateFileName,
+			expectedLocal: true,
+		},
+		{
+			name:          "local backend with custom path",
+			backend:       `{"version": 3, "backend": {"type": "local", "config": {"path": "states/cluster.tfstate"}}}`,
+			expectedPath:  filepath.Join("states", "cluster.tfstate"),
+			expectedLocal: true,
+		},
+		{
+			name:    "remote backend",
+			backend: `{"version": 3, "backend": {"type": "s3", "config": {"bucket": "states", "key": "cluster"}}}`,
+		},
+		{
+			name:    "invalid backend configuration",
+			backend: `{"version": 3, "backend":`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			// the stale state left over from before migrating to the backend
+			if err := ioutil.WriteFile(filepath.Join(dir, StateFileName), []byte(`{"version": 4}`), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if tc.backend != "" {
+				if err := os.MkdirAll(filepath.Join(dir, ".terraform"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(filepath.Join(dir, ".terraform", StateFileName), []byte(tc.backend), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			path, ok := LocalStatePath(dir)
+			if ok != tc.expectedLocal {
+				t.Fatalf("expected the local state to be used: %v, got %v", tc.expectedLocal, ok)
+			}
+			if ok && path != filepath.Join(dir, tc.expectedPath) {
+				t.Errorf("expected the state path %q, got %q", filepath.Join(dir, tc.expectedPath), path)
+			}
+		})
+	}
+}
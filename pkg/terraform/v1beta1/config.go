@@ -66,16 +66,39 @@ type controlPlane struct {
 }
 
 type hostsSpec struct {
-	PublicAddress     []string `json:"public_address"`
-	PrivateAddress    []string `json:"private_address"`
-	Hostnames         []string `json:"hostnames"`
-	SSHUser           string   `json:"ssh_user"`
-	SSHPort           int      `json:"ssh_port"`
-	SSHPrivateKeyFile string   `json:"ssh_private_key_file"`
-	SSHAgentSocket    string   `json:"ssh_agent_socket"`
-	Bastion           string   `json:"bastion"`
-	BastionPort       int      `json:"bastion_port"`
-	BastionUser       string   `json:"bastion_user"`
+	PublicAddress               []string `json:"public_address"`
+	PrivateAddress              []string `json:"private_address"`
+	Hostnames                   []string `json:"hostnames"`
+	SSHUser                     string   `json:"ssh_user"`
+	SSHPort                     int      `json:"ssh_port"`
+	SSHPrivateKeyFile           string   `json:"ssh_private_key_file"`
+	SSHPrivateKeyPassphraseFile string   `json:"ssh_private_key_passphrase_file"`
+	SSHCertificateFile          string   `json:"ssh_certificate_file"`
+	SSHAgentSocket              string   `json:"ssh_agent_socket"`
+	Bastion                     string   `json:"bastion"`
+	BastionPort                 int      `json:"bastion_port"`
+	BastionUser                 string   `json:"bastion_user"`
+
+	BastionHostPublicKey string        `json:"bastion_host_public_key"`
+	SSHHostPublicKeys    []string      `json:"ssh_host_public_keys"`
+	SSHHostKeyCheck      string        `json:"ssh_host_key_check"`
+	SSHKnownHostsFile    string        `json:"ssh_known_hosts_file"`
+	SSHConfigFile        string        `json:"ssh_config_file"`
+	SSHFileTransfer      string        `json:"ssh_file_transfer"`
+	PrivilegeEscalation  string        `json:"privilege_escalation"`
+	SudoPasswordFile     string        `json:"sudo_password_file"`
+	Bastions             []bastionSpec `json:"bastions"`
+}
+
+type bastionSpec struct {
+	Address                     string `json:"address"`
+	Port                        int    `json:"port"`
+	User                        string `json:"user"`
+	SSHPrivateKeyFile           string `json:"ssh_private_key_file"`
+	SSHPrivateKeyPassphraseFile string `json:"ssh_private_key_passphrase_file"`
+	SSHCertificateFile          string `json:"ssh_certificate_file"`
+	SSHAgentSocket              string `json:"ssh_agent_socket"`
+	HostPublicKey               string `json:"host_public_key"`
 }
 
 type hostConfigsOpts func([]kubeonev1beta1.HostConfig)
@@ -127,7 +150,7 @@ func (hs *hostsSpec) toHostConfigs(opts ...hostConfigsOpts) []kubeonev1beta1.Hos
 			hostname = hs.Hostnames[i]
 		}
 
-		hosts = append(hosts, newHostConfig(publicIP, privateIP, hostname, hs))
+		hosts = append(hosts, newHostConfig(publicIP, privateIP, hostname, hs.sshHostPublicKey(i), hs))
 	}
 
 	if len(hosts) == 0 {
@@ -138,7 +161,7 @@ func (hs *hostsSpec) toHostConfigs(opts ...hostConfigsOpts) []kubeonev1beta1.Hos
 				hostname = hs.Hostnames[i]
 			}
 
-			hosts = append(hosts, newHostConfig("", privateIP, hostname, hs))
+			hosts = append(hosts, newHostConfig("", privateIP, hostname, hs.sshHostPublicKey(i), hs))
 		}
 	}
 
@@ -149,6 +172,14 @@ func (hs *hostsSpec) toHostConfigs(opts ...hostConfigsOpts) []kubeonev1beta1.Hos
 	return hosts
 }
 
+func (hs *hostsSpec) sshHostPublicKey(i int) string {
+	if i < len(hs.SSHHostPublicKeys) {
+		return hs.SSHHostPublicKeys[i]
+	}
+
+	return ""
+}
+
 type cloudProviderFlags struct {
 	key   string
 	value interface{}
@@ -272,18 +303,43 @@ func (c *Config) Apply(cluster *kubeonev1beta1.KubeOneCluster) error {
 	return nil
 }
 
-func newHostConfig(publicIP, privateIP, hostname string, hs *hostsSpec) kubeonev1beta1.HostConfig {
+func newHostConfig(publicIP, privateIP, hostname, sshHostPublicKey string, hs *hostsSpec) kubeonev1beta1.HostConfig {
+	var bastions []kubeonev1beta1.BastionConfig
+	for _, b := range hs.Bastions {
+		bastions = append(bastions, kubeonev1beta1.BastionConfig{
+			Address:                     b.Address,
+			Port:                        b.Port,
+			User:                        b.User,
+			SSHPrivateKeyFile:           b.SSHPrivateKeyFile,
+			SSHPrivateKeyPassphraseFile: b.SSHPrivateKeyPassphraseFile,
+			SSHCertificateFile:          b.SSHCertificateFile,
+			SSHAgentSocket:              b.SSHAgentSocket,
+			HostPublicKey:               b.HostPublicKey,
+		})
+	}
+
 	return kubeonev1beta1.HostConfig{
-		Bastion:           hs.Bastion,
-		BastionPort:       hs.BastionPort,
-		BastionUser:       hs.BastionUser,
-		Hostname:          hostname,
-		PrivateAddress:    privateIP,
-		PublicAddress:     publicIP,
-		SSHAgentSocket:    hs.SSHAgentSocket,
-		SSHPrivateKeyFile: hs.SSHPrivateKeyFile,
-		SSHUsername:       hs.SSHUser,
-		SSHPort:           hs.SSHPort,
+		Bastion:                     hs.Bastion,
+		BastionPort:                 hs.BastionPort,
+		BastionUser:                 hs.BastionUser,
+		BastionHostPublicKey:        hs.BastionHostPublicKey,
+		Bastions:                    bastions,
+		Hostname:                    hostname,
+		PrivateAddress:              privateIP,
+		PublicAddress:               publicIP,
+		SSHAgentSocket:              hs.SSHAgentSocket,
+		SSHPrivateKeyFile:           hs.SSHPrivateKeyFile,
+		SSHPrivateKeyPassphraseFile: hs.SSHPrivateKeyPassphraseFile,
+		SSHCertificateFile:          hs.SSHCertificateFile,
+		SSHUsername:                 hs.SSHUser,
+		SSHPort:                     hs.SSHPort,
+		SSHHostPublicKey:            sshHostPublicKey,
+		SSHHostKeyCheck:             kubeonev1beta1.SSHHostKeyCheck(hs.SSHHostKeyCheck),
+		SSHKnownHostsFile:           hs.SSHKnownHostsFile,
+		SSHConfigFile:               hs.SSHConfigFile,
+		SSHFileTransfer:             kubeonev1beta1.SSHFileTransfer(hs.SSHFileTransfer),
+		PrivilegeEscalation:         kubeonev1beta1.PrivilegeEscalation(hs.PrivilegeEscalation),
+		SudoPasswordFile:            hs.SudoPasswordFile,
 	}
 }
 